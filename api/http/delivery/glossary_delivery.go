@@ -0,0 +1,143 @@
+package delivery
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/utils"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type GlossaryDelivery struct {
+	GlossaryUseCase domain.GlossaryUseCase
+}
+
+func (gd *GlossaryDelivery) Create(ctx *gin.Context) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+
+	var body domain.GlossaryBody
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid request body. Please check your input."))
+		return
+	}
+
+	glossary := &domain.Glossary{
+		UserID:  userData.ID,
+		Term:    body.Term,
+		Aliases: body.Aliases,
+	}
+
+	if err := gd.GlossaryUseCase.Create(glossary); err != nil {
+		gd.handleError(ctx, err, "glossary_create", userData.ID)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, glossary)
+}
+
+func (gd *GlossaryDelivery) FindAll(ctx *gin.Context) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+
+	glossaries, err := gd.GlossaryUseCase.FindByUserID(userData.ID)
+	if err != nil {
+		gd.handleError(ctx, err, "glossary_lookup", userData.ID)
+		return
+	}
+
+	if glossaries == nil {
+		glossaries = []*domain.Glossary{}
+	}
+
+	ctx.JSON(http.StatusOK, glossaries)
+}
+
+func (gd *GlossaryDelivery) Update(ctx *gin.Context) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+
+	id, err := bson.ObjectIDFromHex(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid glossary ID."))
+		return
+	}
+
+	var body domain.GlossaryBody
+	if err = ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid request body. Please check your input."))
+		return
+	}
+
+	glossary, err := gd.GlossaryUseCase.Update(id, userData.ID, body)
+	if err != nil {
+		gd.handleError(ctx, err, "glossary_update", userData.ID)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, glossary)
+}
+
+func (gd *GlossaryDelivery) Delete(ctx *gin.Context) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+
+	id, err := bson.ObjectIDFromHex(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid glossary ID."))
+		return
+	}
+
+	if err = gd.GlossaryUseCase.Delete(id, userData.ID); err != nil {
+		gd.handleError(ctx, err, "glossary_delete", userData.ID)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewMessageResponse("Glossary entry deleted."))
+}
+
+func (gd *GlossaryDelivery) handleError(ctx *gin.Context, err error, action string, userID bson.ObjectID) {
+	var validationErrors validator.ValidationErrors
+
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		ctx.JSON(http.StatusNotFound, utils.NewMessageResponse("Glossary entry not found."))
+	case mongo.IsDuplicateKeyError(err):
+		ctx.JSON(http.StatusConflict, utils.NewMessageResponse("This term is already in your glossary."))
+	case errors.Is(err, utils.ErrGlossaryLimitReached):
+		ctx.JSON(http.StatusForbidden, utils.NewMessageResponse("You have reached the maximum number of glossary entries."))
+	case errors.As(err, &validationErrors):
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Term is required; terms and aliases are limited to 100 characters and 20 aliases per term."))
+	default:
+		slog.Error("Glossary request failed",
+			slog.String("action", action),
+			slog.String("user_id", userID.Hex()),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+	}
+}
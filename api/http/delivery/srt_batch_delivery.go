@@ -0,0 +1,391 @@
+package delivery
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kwa0x2/SmartSRT-Backend/api/middleware"
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
+	"github.com/kwa0x2/SmartSRT-Backend/rabbitmq"
+	"github.com/kwa0x2/SmartSRT-Backend/utils"
+	"github.com/kwa0x2/SmartSRT-Backend/utils/validator"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const batchMaxEntrySize int64 = 512 << 20
+
+// batchFile is one media file of a batch, uploaded as is or extracted from a ZIP.
+type batchFile struct {
+	name     string
+	size     int64
+	file     multipart.File
+	duration float64
+}
+
+// spooledFile is an extracted ZIP entry kept in a temporary file, which Close removes.
+type spooledFile struct {
+	*os.File
+}
+
+func (f spooledFile) Close() error {
+	return errors.Join(f.File.Close(), os.Remove(f.Name()))
+}
+
+func (sd *SRTDelivery) CreateBatch(ctx *gin.Context) {
+	startTime := time.Now()
+
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+
+	form, err := ctx.MultipartForm()
+	if err != nil || len(form.File["files"]) == 0 {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("At least one file is required. Please try again."))
+		return
+	}
+
+	params, err := validator.ValidateConversionParams(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse(err.Error()))
+		return
+	}
+
+	files, err := collectBatchFiles(form.File["files"])
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse(err.Error()))
+		return
+	}
+	defer closeBatchFiles(files)
+
+	if len(files) == 0 {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("No media files found. Only mp4, mp3 and wav files are accepted."))
+		return
+	}
+
+	var totalDuration float64
+	for i := range files {
+		fileType, ok := validateMediaType(ctx, userData, files[i].name)
+		if !ok {
+			return
+		}
+
+		duration, err := utils.ProbeMediaDuration(files[i].file, files[i].size, fileType)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("Failed to get file duration for "+files[i].name+". Please try again."))
+			return
+		}
+
+		if !validateMediaDuration(ctx, userData, duration) {
+			return
+		}
+
+		files[i].duration = duration
+		totalDuration += duration
+	}
+
+	if !sd.checkUsageLimit(ctx, userData, totalDuration) {
+		return
+	}
+
+	batch := &domain.Batch{
+		BatchID:   utils.GenerateUUID(),
+		UserID:    userData.ID,
+		FileCount: len(files),
+		Duration:  totalDuration,
+	}
+
+	// Every file gets its job before anything is queued, so a file can never be counted
+	// as pending without a job that will eventually complete or fail.
+	fileIDs, err := sd.createBatchJobs(userData, batch.BatchID, files)
+	if err == nil {
+		if err = sd.BatchUseCase.Create(batch); err != nil {
+			sd.rollbackBatchJobs(userData, batch.BatchID)
+		}
+	}
+	if err != nil {
+		slog.Error("Failed to create conversion batch",
+			slog.String("action", "srt_batch_create"),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("Failed to queue conversion. Please try again."))
+		return
+	}
+
+	for i, file := range files {
+		sd.enqueueBatchFile(userData, params, batch.BatchID, fileIDs[i], file)
+	}
+
+	status, err := sd.BatchUseCase.FindStatus(batch.BatchID, userData.ID)
+	if err != nil {
+		slog.Error("Failed to lookup conversion batch",
+			slog.String("action", "srt_batch_lookup"),
+			slog.String("batch_id", batch.BatchID),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred while retrieving the batch status. Please try again later or contact support."))
+		return
+	}
+
+	middleware.RecordSRTMetrics("queued_success", time.Since(startTime))
+	ctx.JSON(http.StatusAccepted, status)
+}
+
+// createBatchJobs records a queued job for every file and returns their file IDs. If any
+// job cannot be created, the ones already recorded are removed again.
+func (sd *SRTDelivery) createBatchJobs(userData *domain.User, batchID string, files []batchFile) ([]string, error) {
+	fileIDs := make([]string, len(files))
+	for i, file := range files {
+		fileIDs[i] = utils.GenerateUUID()
+
+		job := &domain.ConversionJob{
+			FileID:   fileIDs[i],
+			UserID:   userData.ID,
+			BatchID:  batchID,
+			FileName: file.name,
+			Duration: file.duration,
+		}
+
+		if err := sd.ConversionJobUseCase.Create(job); err != nil {
+			sd.rollbackBatchJobs(userData, batchID)
+			return nil, err
+		}
+	}
+
+	return fileIDs, nil
+}
+
+func (sd *SRTDelivery) rollbackBatchJobs(userData *domain.User, batchID string) {
+	if err := sd.ConversionJobUseCase.DeleteByBatchID(batchID, userData.ID); err != nil {
+		slog.Error("Failed to remove jobs of an aborted batch",
+			slog.String("action", "conversion_job_rollback"),
+			slog.String("batch_id", batchID),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("error", err.Error()))
+	}
+}
+
+// enqueueBatchFile stages file and queues the conversion of its job. Errors fail the job
+// so they surface in the batch status instead of aborting the batch.
+func (sd *SRTDelivery) enqueueBatchFile(userData *domain.User, params *validator.ConversionParams, batchID, fileID string, file batchFile) {
+	sd.updateJobStatus(fileID, types.JobUploading)
+
+	storedFileName, err := sd.SRTUseCase.UploadFile(domain.FileConversionRequest{
+		UserID:       userData.ID,
+		FileName:     file.name,
+		File:         file.file,
+		FileHeader:   multipart.FileHeader{Filename: file.name, Size: file.size},
+		FileDuration: file.duration,
+	})
+	if err == nil {
+		msg := domain.ConversionMessage{
+			FileName:       file.name,
+			StoredFileName: storedFileName,
+			FileSize:       file.size,
+			FileDuration:   file.duration,
+		}
+		applyConversionParams(&msg, userData, params)
+		msg.FileID = fileID
+
+		err = rabbitmq.EnqueueConversionMessage(sd.RabbitMQ, msg)
+	}
+	if err == nil {
+		return
+	}
+
+	if !utils.IsNormalBusinessError(err) {
+		slog.Error("Failed to queue batch file",
+			slog.String("action", "srt_batch_enqueue"),
+			slog.String("batch_id", batchID),
+			slog.String("file_id", fileID),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("error", err.Error()))
+	}
+	sd.failJob(fileID, err)
+}
+
+func (sd *SRTDelivery) FindBatch(ctx *gin.Context) {
+	status, ok := sd.findBatchStatus(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, status)
+}
+
+func (sd *SRTDelivery) DownloadBatch(ctx *gin.Context) {
+	status, ok := sd.findBatchStatus(ctx)
+	if !ok {
+		return
+	}
+
+	if status.Completed == 0 {
+		ctx.JSON(http.StatusConflict, utils.NewMessageResponse("No subtitles are ready for this batch yet."))
+		return
+	}
+
+	ctx.Header("Content-Type", "application/zip")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "smartsrt-"+status.BatchID+".zip"))
+	ctx.Status(http.StatusOK)
+
+	// Headers are already sent, so a failure can only be logged.
+	if err := sd.BatchUseCase.WriteArchive(status.Jobs, ctx.Writer); err != nil {
+		slog.Error("Failed to write batch archive",
+			slog.String("action", "srt_batch_download"),
+			slog.String("batch_id", status.BatchID),
+			slog.String("error", err.Error()))
+	}
+}
+
+func (sd *SRTDelivery) findBatchStatus(ctx *gin.Context) (*domain.BatchStatusResponse, bool) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return nil, false
+	}
+
+	userData := user.(*domain.User)
+	batchID := ctx.Param("batchID")
+
+	status, err := sd.BatchUseCase.FindStatus(batchID, userData.ID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.JSON(http.StatusNotFound, utils.NewMessageResponse("Batch not found."))
+			return nil, false
+		}
+		slog.Error("Failed to lookup conversion batch",
+			slog.String("action", "srt_batch_lookup"),
+			slog.String("batch_id", batchID),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred while retrieving the batch status. Please try again later or contact support."))
+		return nil, false
+	}
+
+	return status, true
+}
+
+// collectBatchFiles opens the uploaded files, expanding ZIP archives into their media
+// entries. Non-media entries such as folder metadata are skipped.
+func collectBatchFiles(headers []*multipart.FileHeader) ([]batchFile, error) {
+	var files []batchFile
+	var extracted int64
+
+	for _, header := range headers {
+		file, err := header.Open()
+		if err != nil {
+			closeBatchFiles(files)
+			return nil, fmt.Errorf("failed to read %s", header.Filename)
+		}
+
+		if !strings.EqualFold(filepath.Ext(header.Filename), ".zip") {
+			files = append(files, batchFile{name: filepath.Base(header.Filename), size: header.Size, file: file})
+		} else {
+			var entries []batchFile
+			entries, err = extractBatchArchive(file, header.Size, &extracted)
+			file.Close()
+			if err != nil {
+				closeBatchFiles(files)
+				return nil, err
+			}
+			files = append(files, entries...)
+		}
+
+		if len(files) > domain.MaxBatchFiles {
+			closeBatchFiles(files)
+			return nil, fmt.Errorf("a batch can contain at most %d files", domain.MaxBatchFiles)
+		}
+	}
+
+	return files, nil
+}
+
+// extractBatchArchive spools the media entries of a ZIP archive to temporary files, so
+// no entry is ever held in memory. extracted tracks the bytes written across archives.
+func extractBatchArchive(file io.ReaderAt, size int64, extracted *int64) ([]batchFile, error) {
+	reader, err := zip.NewReader(file, size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ZIP archive")
+	}
+
+	var files []batchFile
+	for _, entry := range reader.File {
+		name := path.Base(entry.Name)
+		if entry.FileInfo().IsDir() || strings.HasPrefix(entry.Name, "__MACOSX/") || strings.HasPrefix(name, ".") || !utils.IsValidMediaFile(path.Ext(name)) {
+			continue
+		}
+
+		if len(files) >= domain.MaxBatchFiles {
+			closeBatchFiles(files)
+			return nil, fmt.Errorf("a batch can contain at most %d files", domain.MaxBatchFiles)
+		}
+
+		spooled, err := spoolArchiveEntry(entry, name, extracted)
+		if err != nil {
+			closeBatchFiles(files)
+			return nil, err
+		}
+		files = append(files, spooled)
+	}
+
+	return files, nil
+}
+
+// spoolArchiveEntry copies one ZIP entry to a temporary file. The declared size is only
+// a hint, so the copy itself is bounded by the entry and total limits.
+func spoolArchiveEntry(entry *zip.File, name string, extracted *int64) (batchFile, error) {
+	limit := min(batchMaxEntrySize, uploadMaxSize-*extracted)
+	if entry.UncompressedSize64 > uint64(limit) {
+		return batchFile{}, fmt.Errorf("%s is too large", name)
+	}
+
+	rc, err := entry.Open()
+	if err != nil {
+		return batchFile{}, fmt.Errorf("failed to read %s from the ZIP archive", name)
+	}
+	defer rc.Close()
+
+	temp, err := os.CreateTemp("", "smartsrt-batch-*"+path.Ext(name))
+	if err != nil {
+		return batchFile{}, err
+	}
+	spooled := spooledFile{temp}
+
+	written, err := io.Copy(temp, io.LimitReader(rc, limit+1))
+	if err != nil {
+		spooled.Close()
+		return batchFile{}, fmt.Errorf("failed to read %s from the ZIP archive", name)
+	}
+	if written > limit {
+		spooled.Close()
+		return batchFile{}, fmt.Errorf("%s is too large", name)
+	}
+	if _, err = temp.Seek(0, io.SeekStart); err != nil {
+		spooled.Close()
+		return batchFile{}, err
+	}
+
+	*extracted += written
+	return batchFile{name: name, size: written, file: spooled}, nil
+}
+
+func closeBatchFiles(files []batchFile) {
+	for _, file := range files {
+		file.file.Close()
+	}
+}
@@ -1,12 +1,17 @@
 package delivery
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"mime/multipart"
 	"net/http"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
 
@@ -15,12 +20,21 @@ import (
 	"github.com/kwa0x2/SmartSRT-Backend/domain"
 	"github.com/kwa0x2/SmartSRT-Backend/rabbitmq"
 	"github.com/kwa0x2/SmartSRT-Backend/utils"
+	"github.com/kwa0x2/SmartSRT-Backend/utils/subtitle"
 	"github.com/kwa0x2/SmartSRT-Backend/utils/validator"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
+const uploadMaxSize int64 = 2 << 30
+
 type SRTDelivery struct {
-	SRTUseCase domain.SRTUseCase
-	RabbitMQ   *domain.RabbitMQ
+	SRTUseCase           domain.SRTUseCase
+	ConversionJobUseCase domain.ConversionJobUseCase
+	UploadUseCase        domain.UploadUseCase
+	UsageUseCase         domain.UsageUseCase
+	BatchUseCase         domain.BatchUseCase
+	RabbitMQ             *domain.RabbitMQ
 }
 
 func (sd *SRTDelivery) ConvertFileToSRT(ctx *gin.Context) {
@@ -46,46 +60,153 @@ func (sd *SRTDelivery) ConvertFileToSRT(ctx *gin.Context) {
 		}
 	}(file)
 
-	fileType := filepath.Ext(header.Filename)
+	fileType, ok := validateMediaType(ctx, userData, header.Filename)
+	if !ok {
+		return
+	}
 
-	if userData.Plan != types.Pro && fileType == ".wav" {
-		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("You need to upgrade to the Pro plan to upload WAV files."))
+	duration, err := utils.ProbeMediaDuration(file, header.Size, fileType)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("Failed to get file duration. Please try again."))
 		return
 	}
 
-	if !utils.IsValidMediaFile(fileType) {
-		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid file format. Only mp4, mp3 and wav files are accepted."))
+	if !validateMediaDuration(ctx, userData, duration) {
+		return
+	}
+
+	params, err := validator.ValidateConversionParams(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse(err.Error()))
 		return
 	}
 
-	seeker, ok := file.(io.Seeker)
+	fileID, ok := sd.createConversionJob(ctx, userData, header.Filename, duration)
 	if !ok {
-		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("Failed to process file. Please try again."))
 		return
 	}
 
-	duration, err := utils.GetMediaDuration(file, fileType)
+	sd.updateJobStatus(fileID, types.JobUploading)
+	storedFileName, err := sd.SRTUseCase.UploadFile(domain.FileConversionRequest{
+		UserID:       userData.ID,
+		FileName:     header.Filename,
+		File:         file,
+		FileHeader:   *header,
+		FileDuration: duration,
+	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("Failed to get file duration. Please try again."))
+		sd.failJob(fileID, err)
+		if errors.Is(err, utils.ErrLimitReached) {
+			ctx.JSON(http.StatusForbidden, utils.NewMessageResponse("You have reached your monthly usage limit."))
+			return
+		}
+		slog.Error("Failed to stage file for conversion",
+			slog.String("action", "srt_file_staging"),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("file_name", header.Filename),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("Failed to upload file. Please try again."))
 		return
 	}
 
-	maxDuration := 30 * time.Second
-	if userData.Plan == types.Pro {
-		maxDuration = 5 * time.Minute
+	sd.enqueueConversion(ctx, startTime, userData, params, fileID, domain.ConversionMessage{
+		FileName:       header.Filename,
+		StoredFileName: storedFileName,
+		FileSize:       header.Size,
+		FileDuration:   duration,
+	})
+}
+
+func (sd *SRTDelivery) InitiateUpload(ctx *gin.Context) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
 	}
 
-	fileDuration := time.Duration(duration * float64(time.Second))
-	if fileDuration > maxDuration {
-		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse(
-			"File duration exceeds the limit. Maximum duration is "+maxDuration.String()+" for your plan.",
-		))
+	userData := user.(*domain.User)
+
+	var body domain.InitiateUploadBody
+	if err := ctx.ShouldBindJSON(&body); err != nil || body.FileName == "" || body.FileSize <= 0 {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid request body. Please check your input."))
+		return
+	}
+
+	if body.FileSize > uploadMaxSize {
+		ctx.JSON(http.StatusRequestEntityTooLarge, utils.NewMessageResponse("File is too large."))
+		return
+	}
+
+	body.FileName = filepath.Base(body.FileName)
+	if _, ok := validateMediaType(ctx, userData, body.FileName); !ok {
+		return
+	}
+
+	upload, uploadURL, err := sd.UploadUseCase.Initiate(userData.ID, body.FileName, body.FileSize)
+	if err != nil {
+		slog.Error("Failed to initiate presigned upload",
+			slog.String("action", "srt_upload_initiate"),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("file_name", body.FileName),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("Failed to prepare upload. Please try again."))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"upload":     upload,
+		"upload_url": uploadURL,
+		"method":     http.MethodPut,
+	})
+}
+
+func (sd *SRTDelivery) CompleteUpload(ctx *gin.Context) {
+	startTime := time.Now()
+
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+
+	uploadID, err := bson.ObjectIDFromHex(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid upload ID."))
 		return
 	}
 
-	_, err = seeker.Seek(0, io.SeekStart)
+	upload, err := sd.UploadUseCase.FindOneByIDAndUserID(uploadID, userData.ID)
+	if err == nil && upload.Method != types.UploadPresigned {
+		// Resumable uploads are completed by their final PATCH.
+		err = mongo.ErrNoDocuments
+	}
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("Failed to process file. Please try again."))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.JSON(http.StatusNotFound, utils.NewMessageResponse("Upload not found."))
+			return
+		}
+		slog.Error("Failed to lookup upload",
+			slog.String("action", "srt_upload_lookup"),
+			slog.String("upload_id", uploadID.Hex()),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	if upload.Status != types.UploadPending {
+		ctx.JSON(http.StatusConflict, utils.NewMessageResponse("This upload has already been completed."))
+		return
+	}
+
+	if time.Now().UTC().After(upload.ExpiresAt) {
+		ctx.JSON(http.StatusGone, utils.NewMessageResponse("This upload has expired. Please upload the file again."))
+		return
+	}
+
+	if _, ok := validateMediaType(ctx, userData, upload.FileName); !ok {
 		return
 	}
 
@@ -95,80 +216,1219 @@ func (sd *SRTDelivery) ConvertFileToSRT(ctx *gin.Context) {
 		return
 	}
 
-	fileID := utils.GenerateUUID()
+	duration, err := sd.UploadUseCase.GetMediaDuration(upload)
+	if err != nil {
+		slog.Warn("Failed to read duration of uploaded file",
+			slog.String("action", "srt_upload_duration"),
+			slog.String("upload_id", uploadID.Hex()),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Failed to get file duration. Make sure the file was uploaded completely and try again."))
+		return
+	}
+
+	if !validateMediaDuration(ctx, userData, duration) {
+		return
+	}
+
+	if !sd.checkUsageLimit(ctx, userData, duration) {
+		return
+	}
+
+	if err = sd.UploadUseCase.MarkCompleted(upload.ID); err != nil {
+		if errors.Is(err, utils.ErrUploadCompleted) {
+			ctx.JSON(http.StatusConflict, utils.NewMessageResponse("This upload has already been completed."))
+			return
+		}
+		slog.Error("Failed to mark upload as completed",
+			slog.String("action", "srt_upload_complete"),
+			slog.String("upload_id", uploadID.Hex()),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	// The file is already in storage, so the job skips the uploading stage.
+	fileID, ok := sd.createConversionJob(ctx, userData, upload.FileName, duration)
+	if !ok {
+		sd.reopenUpload(upload)
+		return
+	}
+
+	if !sd.enqueueConversion(ctx, startTime, userData, params, fileID, domain.ConversionMessage{
+		FileName:       upload.FileName,
+		StoredFileName: upload.StoredFileName,
+		FileSize:       upload.Length,
+		FileDuration:   duration,
+	}) {
+		sd.reopenUpload(upload)
+	}
+}
+
+func (sd *SRTDelivery) TusOptions(ctx *gin.Context) {
+	setTusHeaders(ctx)
+	ctx.Header("Tus-Version", utils.TusVersion)
+	ctx.Header("Tus-Extension", "creation")
+	ctx.Header("Tus-Max-Size", strconv.FormatInt(uploadMaxSize, 10))
+	ctx.Status(http.StatusNoContent)
+}
+
+func (sd *SRTDelivery) TusCreate(ctx *gin.Context) {
+	setTusHeaders(ctx)
+	if !checkTusResumable(ctx) {
+		return
+	}
+
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+
+	length, err := strconv.ParseInt(ctx.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || length <= 0 {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Upload-Length header is required."))
+		return
+	}
+
+	if length > uploadMaxSize {
+		ctx.JSON(http.StatusRequestEntityTooLarge, utils.NewMessageResponse("File is too large."))
+		return
+	}
 
-	fileBytes, err := io.ReadAll(file)
+	metadata, err := utils.ParseTusMetadata(ctx.GetHeader("Upload-Metadata"))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("Failed to read file. Please try again."))
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid Upload-Metadata header."))
 		return
 	}
 
-	msg := domain.ConversionMessage{
-		UserID:              userData.ID,
-		WordsPerLine:        params.WordsPerLine,
-		Punctuation:         params.Punctuation,
-		ConsiderPunctuation: params.ConsiderPunctuation,
-		FileID:              fileID,
-		FileName:            header.Filename,
-		FileContent:         fileBytes,
-		FileSize:            header.Size,
-		FileDuration:        duration,
-		Email:               userData.Email,
+	fileName := filepath.Base(metadata["filename"])
+	if metadata["filename"] == "" {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("File name is required in upload metadata."))
+		return
 	}
 
-	response, err := rabbitmq.PublishConversionMessage(sd.RabbitMQ, ctx, msg)
+	if _, ok := validateMediaType(ctx, userData, fileName); !ok {
+		return
+	}
+
+	if _, err = validator.ParseConversionParams(func(key string) string { return metadata[key] }); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse(err.Error()))
+		return
+	}
+
+	upload, err := sd.UploadUseCase.CreateResumable(userData.ID, fileName, length, metadata)
 	if err != nil {
-		if err.Error() == "Response timeout" {
-			if !utils.IsNormalBusinessError(err) {
-				slog.Error("RabbitMQ conversion request timeout",
-					slog.String("action", "rabbitmq_conversion_timeout"),
-					slog.String("file_id", fileID),
-					slog.String("user_id", userData.ID.Hex()),
-					slog.String("error", err.Error()))
-			}
-			ctx.JSON(http.StatusAccepted, gin.H{
-				"message": "Your file is being processed. You will receive an email when it's ready.",
-				"file_id": fileID,
-			})
-			return
-		} else {
-			if !utils.IsNormalBusinessError(err) {
-				slog.Error("Failed to publish conversion message to RabbitMQ",
-					slog.String("action", "rabbitmq_conversion_publish"),
-					slog.String("file_id", fileID),
-					slog.String("user_id", userData.ID.Hex()),
-					slog.String("error", err.Error()))
-			}
-			ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("Failed to queue conversion. Please try again."))
+		slog.Error("Failed to create resumable upload",
+			slog.String("action", "srt_tus_create"),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("file_name", fileName),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("Failed to prepare upload. Please try again."))
+		return
+	}
+
+	ctx.Header("Location", strings.TrimSuffix(ctx.Request.URL.Path, "/")+"/"+upload.ID.Hex())
+	ctx.Header("Upload-Expires", upload.ExpiresAt.Format(http.TimeFormat))
+	ctx.Status(http.StatusCreated)
+}
+
+func (sd *SRTDelivery) TusHead(ctx *gin.Context) {
+	setTusHeaders(ctx)
+	ctx.Header("Cache-Control", "no-store")
+
+	upload, ok := sd.findTusUpload(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	ctx.Header("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	ctx.Header("Upload-Expires", upload.ExpiresAt.Format(http.TimeFormat))
+	ctx.Status(http.StatusOK)
+}
+
+func (sd *SRTDelivery) TusPatch(ctx *gin.Context) {
+	startTime := time.Now()
+
+	setTusHeaders(ctx)
+	if !checkTusResumable(ctx) {
+		return
+	}
+
+	if ctx.ContentType() != "application/offset+octet-stream" {
+		ctx.JSON(http.StatusUnsupportedMediaType, utils.NewMessageResponse("Content-Type must be application/offset+octet-stream."))
+		return
+	}
+
+	offset, err := strconv.ParseInt(ctx.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Upload-Offset header is required."))
+		return
+	}
+
+	upload, ok := sd.findTusUpload(ctx)
+	if !ok {
+		return
+	}
+
+	if upload.Status != types.UploadPending {
+		ctx.JSON(http.StatusConflict, utils.NewMessageResponse("This upload has already been completed."))
+		return
+	}
+
+	if time.Now().UTC().After(upload.ExpiresAt) {
+		ctx.JSON(http.StatusGone, utils.NewMessageResponse("This upload has expired. Please upload the file again."))
+		return
+	}
+
+	newOffset, err := sd.UploadUseCase.AppendChunk(upload, offset, ctx.Request.Body)
+	ctx.Header("Upload-Offset", strconv.FormatInt(newOffset, 10))
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrUploadOffsetMismatch):
+			ctx.JSON(http.StatusConflict, utils.NewMessageResponse("Upload-Offset does not match the current offset."))
+		case errors.Is(err, utils.ErrUploadTooLarge):
+			ctx.JSON(http.StatusRequestEntityTooLarge, utils.NewMessageResponse("Chunk exceeds the declared upload length."))
+		default:
+			slog.Warn("Resumable upload chunk interrupted",
+				slog.String("action", "srt_tus_patch"),
+				slog.String("upload_id", upload.ID.Hex()),
+				slog.Int64("offset", newOffset),
+				slog.String("error", err.Error()))
+			ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("Upload was interrupted. Please resume it."))
+		}
+		return
+	}
+
+	if newOffset < upload.Length {
+		ctx.Status(http.StatusNoContent)
+		return
+	}
+
+	userData := ctx.MustGet("user").(*domain.User)
+
+	params, err := validator.ParseConversionParams(func(key string) string { return upload.Metadata[key] })
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse(err.Error()))
+		return
+	}
+
+	if err = sd.UploadUseCase.AssembleResumable(upload); err != nil {
+		slog.Error("Failed to assemble resumable upload",
+			slog.String("action", "srt_tus_assemble"),
+			slog.String("upload_id", upload.ID.Hex()),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("Failed to upload file. Please try again."))
+		return
+	}
+
+	duration, err := sd.UploadUseCase.GetMediaDuration(upload)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Failed to get file duration. Please try again."))
+		return
+	}
+
+	if !validateMediaDuration(ctx, userData, duration) {
+		return
+	}
+
+	if !sd.checkUsageLimit(ctx, userData, duration) {
+		return
+	}
+
+	if err = sd.UploadUseCase.MarkCompleted(upload.ID); err != nil {
+		if errors.Is(err, utils.ErrUploadCompleted) {
+			ctx.JSON(http.StatusConflict, utils.NewMessageResponse("This upload has already been completed."))
 			return
 		}
+		slog.Error("Failed to mark upload as completed",
+			slog.String("action", "srt_tus_complete"),
+			slog.String("upload_id", upload.ID.Hex()),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
 
+	// The file is already in storage, so the job skips the uploading stage.
+	fileID, ok := sd.createConversionJob(ctx, userData, upload.FileName, duration)
+	if !ok {
+		sd.reopenUpload(upload)
+		return
 	}
 
-	middleware.RecordSRTMetrics("queued_success", time.Since(startTime))
-	ctx.JSON(response.StatusCode, response)
+	if !sd.enqueueConversion(ctx, startTime, userData, params, fileID, domain.ConversionMessage{
+		FileName:       upload.FileName,
+		StoredFileName: upload.StoredFileName,
+		FileSize:       upload.Length,
+		FileDuration:   duration,
+	}) {
+		sd.reopenUpload(upload)
+	}
 }
 
-func (sd *SRTDelivery) FindHistories(ctx *gin.Context) {
+func (sd *SRTDelivery) findTusUpload(ctx *gin.Context) (*domain.Upload, bool) {
 	user, exists := ctx.Get("user")
 	if !exists {
 		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
-		return
+		return nil, false
 	}
 
 	userData := user.(*domain.User)
 
-	srtHistoriesData, err := sd.SRTUseCase.FindHistoriesByUserID(userData.ID)
+	uploadID, err := bson.ObjectIDFromHex(ctx.Param("id"))
 	if err != nil {
-		if !utils.IsNormalBusinessError(err) {
-			slog.Error("Failed to lookup SRT history",
-				slog.String("action", "srt_history_lookup"),
+		ctx.Status(http.StatusNotFound)
+		return nil, false
+	}
+
+	upload, err := sd.UploadUseCase.FindOneByIDAndUserID(uploadID, userData.ID)
+	if err != nil || upload.Method != types.UploadTus {
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			slog.Error("Failed to lookup resumable upload",
+				slog.String("action", "srt_tus_lookup"),
+				slog.String("upload_id", uploadID.Hex()),
 				slog.String("user_id", userData.ID.Hex()),
 				slog.String("error", err.Error()))
+			ctx.Status(http.StatusInternalServerError)
+			return nil, false
 		}
-		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred while retrieving history data. Please try again later or contact support."))
-		return
+		ctx.Status(http.StatusNotFound)
+		return nil, false
 	}
 
-	ctx.JSON(http.StatusOK, srtHistoriesData)
+	return upload, true
+}
+
+func setTusHeaders(ctx *gin.Context) {
+	ctx.Header("Tus-Resumable", utils.TusVersion)
+}
+
+func checkTusResumable(ctx *gin.Context) bool {
+	if ctx.GetHeader("Tus-Resumable") != utils.TusVersion {
+		ctx.Header("Tus-Version", utils.TusVersion)
+		ctx.JSON(http.StatusPreconditionFailed, utils.NewMessageResponse("Unsupported tus protocol version."))
+		return false
+	}
+	return true
+}
+
+func (sd *SRTDelivery) checkUsageLimit(ctx *gin.Context, userData *domain.User, duration float64) bool {
+	canUpload, err := sd.UsageUseCase.CheckUsageLimit(userData.ID, duration)
+	if err != nil {
+		slog.Error("Failed to check usage limit",
+			slog.String("action", "srt_upload_usage_check"),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return false
+	}
+
+	if !canUpload {
+		ctx.JSON(http.StatusForbidden, utils.NewMessageResponse("You have reached your monthly usage limit."))
+		return false
+	}
+
+	return true
+}
+
+func validateMediaType(ctx *gin.Context, userData *domain.User, fileName string) (string, bool) {
+	fileType := filepath.Ext(fileName)
+
+	if userData.Plan != types.Pro && fileType == ".wav" {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("You need to upgrade to the Pro plan to upload WAV files."))
+		return "", false
+	}
+
+	if !utils.IsValidMediaFile(fileType) {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid file format. Only mp4, mp3 and wav files are accepted."))
+		return "", false
+	}
+
+	return fileType, true
+}
+
+func validateMediaDuration(ctx *gin.Context, userData *domain.User, duration float64) bool {
+	maxDuration := 30 * time.Second
+	if userData.Plan == types.Pro {
+		maxDuration = 5 * time.Minute
+	}
+
+	fileDuration := time.Duration(duration * float64(time.Second))
+	if fileDuration > maxDuration {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse(
+			"File duration exceeds the limit. Maximum duration is "+maxDuration.String()+" for your plan.",
+		))
+		return false
+	}
+
+	return true
+}
+
+// createConversionJob records a queued job and returns its file ID. Failures are
+// answered here, in which case ok is false.
+func (sd *SRTDelivery) createConversionJob(ctx *gin.Context, userData *domain.User, fileName string, duration float64) (string, bool) {
+	fileID := utils.GenerateUUID()
+
+	job := &domain.ConversionJob{
+		FileID:   fileID,
+		UserID:   userData.ID,
+		FileName: fileName,
+		Duration: duration,
+	}
+
+	if err := sd.ConversionJobUseCase.Create(job); err != nil {
+		if !utils.IsNormalBusinessError(err) {
+			slog.Error("Failed to create conversion job",
+				slog.String("action", "conversion_job_create"),
+				slog.String("file_id", fileID),
+				slog.String("user_id", userData.ID.Hex()),
+				slog.String("error", err.Error()))
+		}
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("Failed to queue conversion. Please try again."))
+		return "", false
+	}
+
+	return fileID, true
+}
+
+func (sd *SRTDelivery) updateJobStatus(fileID string, status types.JobStatus) {
+	if err := sd.ConversionJobUseCase.UpdateStatus(fileID, status); err != nil {
+		slog.Error("Failed to update conversion job status",
+			slog.String("action", "conversion_job_update"),
+			slog.String("file_id", fileID),
+			slog.String("status", string(status)),
+			slog.String("error", err.Error()))
+	}
+}
+
+func (sd *SRTDelivery) failJob(fileID string, reason error) {
+	if err := sd.ConversionJobUseCase.Fail(fileID, reason.Error()); err != nil {
+		slog.Error("Failed to mark conversion job as failed",
+			slog.String("action", "conversion_job_fail"),
+			slog.String("file_id", fileID),
+			slog.String("error", err.Error()))
+	}
+}
+
+// reopenUpload returns a completed upload to pending after its conversion could not be
+// queued, so completing it again retries the conversion.
+func (sd *SRTDelivery) reopenUpload(upload *domain.Upload) {
+	if err := sd.UploadUseCase.Reopen(upload.ID); err != nil {
+		slog.Error("Failed to reopen upload",
+			slog.String("action", "srt_upload_reopen"),
+			slog.String("upload_id", upload.ID.Hex()),
+			slog.String("error", err.Error()))
+	}
+}
+
+// enqueueConversion publishes the conversion of a staged file for the job fileID. It
+// reports false when the message could not be published and the job was failed.
+func (sd *SRTDelivery) enqueueConversion(ctx *gin.Context, startTime time.Time, userData *domain.User, params *validator.ConversionParams, fileID string, msg domain.ConversionMessage) bool {
+	applyConversionParams(&msg, userData, params)
+	msg.FileID = fileID
+
+	response, err := rabbitmq.PublishConversionMessage(sd.RabbitMQ, ctx, msg)
+	if err != nil {
+		if err.Error() == "Response timeout" {
+			if !utils.IsNormalBusinessError(err) {
+				slog.Error("RabbitMQ conversion request timeout",
+					slog.String("action", "rabbitmq_conversion_timeout"),
+					slog.String("file_id", fileID),
+					slog.String("user_id", userData.ID.Hex()),
+					slog.String("error", err.Error()))
+			}
+			ctx.JSON(http.StatusAccepted, gin.H{
+				"message": "Your file is being processed. You will receive an email when it's ready.",
+				"file_id": fileID,
+			})
+			return true
+		} else {
+			if !utils.IsNormalBusinessError(err) {
+				slog.Error("Failed to publish conversion message to RabbitMQ",
+					slog.String("action", "rabbitmq_conversion_publish"),
+					slog.String("file_id", fileID),
+					slog.String("user_id", userData.ID.Hex()),
+					slog.String("error", err.Error()))
+			}
+			sd.failJob(fileID, err)
+			ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("Failed to queue conversion. Please try again."))
+			return false
+		}
+
+	}
+
+	response.FileID = fileID
+
+	middleware.RecordSRTMetrics("queued_success", time.Since(startTime))
+	ctx.JSON(response.StatusCode, response)
+	return true
+}
+
+func applyConversionParams(msg *domain.ConversionMessage, userData *domain.User, params *validator.ConversionParams) {
+	msg.UserID = userData.ID
+	msg.WordsPerLine = params.WordsPerLine
+	msg.Punctuation = params.Punctuation
+	msg.ConsiderPunctuation = params.ConsiderPunctuation
+	msg.Format = params.Format
+	msg.LineBreak = params.LineBreak
+	msg.Language = params.Language
+	msg.Diarization = params.Diarization
+	msg.TextProcessing = params.TextProcessing
+	msg.Email = userData.Email
+}
+
+func (sd *SRTDelivery) FindHistories(ctx *gin.Context) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+
+	query, err := validator.ParseHistoryQuery(ctx.Query)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse(err.Error()))
+		return
+	}
+
+	srtHistoriesData, err := sd.SRTUseCase.FindHistories(userData.ID, *query)
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidCursor) {
+			ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid page cursor. Please start again from the first page."))
+			return
+		}
+		if !utils.IsNormalBusinessError(err) {
+			slog.Error("Failed to lookup SRT history",
+				slog.String("action", "srt_history_lookup"),
+				slog.String("user_id", userData.ID.Hex()),
+				slog.String("error", err.Error()))
+		}
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred while retrieving history data. Please try again later or contact support."))
+		return
+	}
+
+	// Clients that do not page keep getting the plain array this endpoint always returned.
+	if !query.Paginated {
+		ctx.JSON(http.StatusOK, srtHistoriesData.Items)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, srtHistoriesData)
+}
+
+// DownloadHistory returns a short-lived signed link to the history's subtitle file, or
+// streams it with ?stream=true. Asking for a ?format= always streams, since other
+// formats are rendered on the fly.
+func (sd *SRTDelivery) DownloadHistory(ctx *gin.Context) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+
+	historyID, err := bson.ObjectIDFromHex(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid history ID."))
+		return
+	}
+
+	format := types.SubtitleFormat(strings.ToLower(ctx.Query("format")))
+	if format != "" && !subtitle.IsValidFormat(format) {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("format must be one of srt, vtt, ass, ttml, sbv or txt"))
+		return
+	}
+
+	var stream bool
+	if val := ctx.Query("stream"); val != "" {
+		if stream, err = strconv.ParseBool(val); err != nil {
+			ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("stream must be true or false"))
+			return
+		}
+	}
+
+	if !stream && format == "" {
+		expiresAt := time.Now().UTC().Add(domain.DownloadURLExpiry)
+		downloadURL, err := sd.SRTUseCase.CreateDownloadURL(historyID, userData.ID, domain.DownloadURLExpiry)
+		if err != nil {
+			sd.handleDownloadError(ctx, historyID, userData.ID, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"url": downloadURL, "expires_at": expiresAt})
+		return
+	}
+
+	download, err := sd.SRTUseCase.OpenDownload(historyID, userData.ID, format)
+	if err != nil {
+		sd.handleDownloadError(ctx, historyID, userData.ID, err)
+		return
+	}
+	defer download.Body.Close()
+
+	ctx.DataFromReader(http.StatusOK, -1, download.ContentType+"; charset=utf-8", download.Body, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", download.FileName),
+		"Cache-Control":       "no-store",
+	})
+}
+
+func (sd *SRTDelivery) handleDownloadError(ctx *gin.Context, historyID, userID bson.ObjectID, err error) {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		ctx.JSON(http.StatusNotFound, utils.NewMessageResponse("History or its transcript not found."))
+		return
+	}
+	slog.Error("Failed to download SRT history",
+		slog.String("action", "srt_history_download"),
+		slog.String("history_id", historyID.Hex()),
+		slog.String("user_id", userID.Hex()),
+		slog.String("error", err.Error()))
+	ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred while preparing the download. Please try again later or contact support."))
+}
+
+func (sd *SRTDelivery) RenameHistory(ctx *gin.Context) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+
+	historyID, err := bson.ObjectIDFromHex(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid history ID."))
+		return
+	}
+
+	var body domain.RenameHistoryBody
+	if err = ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid request body"))
+		return
+	}
+
+	fileName := strings.TrimSpace(body.FileName)
+	if fileName == "" || utf8.RuneCountInString(fileName) > 255 || strings.ContainsAny(fileName, "/\\\r\n") {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("File name must be 1 to 255 characters without slashes or line breaks."))
+		return
+	}
+
+	history, err := sd.SRTUseCase.RenameHistory(historyID, userData.ID, fileName)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.JSON(http.StatusNotFound, utils.NewMessageResponse("History not found."))
+			return
+		}
+		slog.Error("Failed to rename SRT history",
+			slog.String("action", "srt_history_rename"),
+			slog.String("history_id", historyID.Hex()),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred while renaming the history. Please try again later or contact support."))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, history)
+}
+
+func (sd *SRTDelivery) DeleteHistory(ctx *gin.Context) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+
+	historyID, err := bson.ObjectIDFromHex(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid history ID."))
+		return
+	}
+
+	if err = sd.SRTUseCase.DeleteHistory(historyID, userData.ID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.JSON(http.StatusNotFound, utils.NewMessageResponse("History not found."))
+			return
+		}
+		slog.Error("Failed to delete SRT history",
+			slog.String("action", "srt_history_delete"),
+			slog.String("history_id", historyID.Hex()),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred while deleting the history. Please try again later or contact support."))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (sd *SRTDelivery) FindDeletedHistories(ctx *gin.Context) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+
+	histories, err := sd.SRTUseCase.FindDeletedHistories(userData.ID)
+	if err != nil {
+		slog.Error("Failed to lookup deleted SRT history",
+			slog.String("action", "srt_history_trash"),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred while retrieving history data. Please try again later or contact support."))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, histories)
+}
+
+func (sd *SRTDelivery) RestoreHistory(ctx *gin.Context) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+
+	historyID, err := bson.ObjectIDFromHex(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid history ID."))
+		return
+	}
+
+	history, err := sd.SRTUseCase.RestoreHistory(historyID, userData.ID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.JSON(http.StatusNotFound, utils.NewMessageResponse("History not found in the trash or its 30 day restore period has passed."))
+			return
+		}
+		slog.Error("Failed to restore SRT history",
+			slog.String("action", "srt_history_restore"),
+			slog.String("history_id", historyID.Hex()),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred while restoring the history. Please try again later or contact support."))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, history)
+}
+
+func (sd *SRTDelivery) SearchTranscripts(ctx *gin.Context) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+
+	query := strings.TrimSpace(ctx.Query("q"))
+	if query == "" || utf8.RuneCountInString(query) > 200 {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Search query must be 1 to 200 characters."))
+		return
+	}
+
+	limit := 20
+	if val := ctx.Query("limit"); val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 1 || n > domain.MaxSearchResults {
+			ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse(fmt.Sprintf("limit must be between 1 and %d", domain.MaxSearchResults)))
+			return
+		}
+		limit = n
+	}
+
+	results, err := sd.SRTUseCase.SearchTranscripts(userData.ID, query, limit)
+	if err != nil {
+		slog.Error("Failed to search transcripts",
+			slog.String("action", "srt_transcript_search"),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred while searching your subtitles. Please try again later or contact support."))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, results)
+}
+
+func (sd *SRTDelivery) FindTranscript(ctx *gin.Context) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+
+	historyID, err := bson.ObjectIDFromHex(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid history ID."))
+		return
+	}
+
+	transcript, err := sd.SRTUseCase.FindTranscriptByHistoryID(historyID, userData.ID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.JSON(http.StatusNotFound, utils.NewMessageResponse("Transcript not found."))
+			return
+		}
+		slog.Error("Failed to lookup transcript",
+			slog.String("action", "transcript_lookup"),
+			slog.String("history_id", historyID.Hex()),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred while retrieving the transcript. Please try again later or contact support."))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transcript)
+}
+
+func (sd *SRTDelivery) RenderHistory(ctx *gin.Context) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+
+	historyID, err := bson.ObjectIDFromHex(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid history ID."))
+		return
+	}
+
+	params, err := validator.ValidateConversionParams(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse(err.Error()))
+		return
+	}
+
+	history, err := sd.SRTUseCase.RenderHistory(domain.RenderRequest{
+		HistoryID:           historyID,
+		UserID:              userData.ID,
+		WordsPerLine:        params.WordsPerLine,
+		Punctuation:         params.Punctuation,
+		ConsiderPunctuation: params.ConsiderPunctuation,
+		Format:              params.Format,
+		LineBreak:           params.LineBreak,
+	})
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.JSON(http.StatusNotFound, utils.NewMessageResponse("History or its transcript not found."))
+			return
+		}
+		slog.Error("Failed to render history",
+			slog.String("action", "srt_render"),
+			slog.String("history_id", historyID.Hex()),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred while rendering the subtitles. Please try again later or contact support."))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, history)
+}
+
+func (sd *SRTDelivery) AdjustHistoryTiming(ctx *gin.Context) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+
+	historyID, err := bson.ObjectIDFromHex(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid history ID."))
+		return
+	}
+
+	adjustment, err := validator.ParseTimingAdjustment(ctx.PostForm)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse(err.Error()))
+		return
+	}
+
+	history, err := sd.SRTUseCase.AdjustHistoryTiming(historyID, userData.ID, *adjustment)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.JSON(http.StatusNotFound, utils.NewMessageResponse("History or its transcript not found."))
+			return
+		}
+		slog.Error("Failed to adjust history timing",
+			slog.String("action", "srt_timing_adjust"),
+			slog.String("history_id", historyID.Hex()),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred while adjusting the subtitle timing. Please try again later or contact support."))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, history)
+}
+
+func (sd *SRTDelivery) TranslateHistory(ctx *gin.Context) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+
+	historyID, err := bson.ObjectIDFromHex(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid history ID."))
+		return
+	}
+
+	targetLanguage, err := validator.ParseLanguage("target_language", ctx.PostForm("target_language"), false)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse(err.Error()))
+		return
+	}
+
+	history, err := sd.SRTUseCase.TranslateHistory(historyID, userData.ID, targetLanguage)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.JSON(http.StatusNotFound, utils.NewMessageResponse("History or its transcript not found."))
+			return
+		}
+		if errors.Is(err, utils.ErrTranslationNotConfigured) {
+			ctx.JSON(http.StatusServiceUnavailable, utils.NewMessageResponse("Translation is not available on this server."))
+			return
+		}
+		slog.Error("Failed to translate history",
+			slog.String("action", "srt_translate"),
+			slog.String("history_id", historyID.Hex()),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("target_language", targetLanguage),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred while translating the subtitles. Please try again later or contact support."))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, history)
+}
+
+func (sd *SRTDelivery) RenameSpeakers(ctx *gin.Context) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+
+	historyID, err := bson.ObjectIDFromHex(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid history ID."))
+		return
+	}
+
+	var body domain.RenameSpeakersBody
+	if err = ctx.ShouldBindJSON(&body); err != nil || len(body.Speakers) == 0 {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid request body"))
+		return
+	}
+
+	for label, name := range body.Speakers {
+		name = strings.TrimSpace(name)
+		// Names end up in markup of the rendered formats, such as WebVTT voice tags.
+		if name == "" || utf8.RuneCountInString(name) > 50 || strings.ContainsAny(name, "\r\n<>&{}\\") {
+			ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Speaker names must be 1 to 50 characters on a single line, without < > & { } or \\."))
+			return
+		}
+		body.Speakers[label] = name
+	}
+
+	history, err := sd.SRTUseCase.RenameSpeakers(historyID, userData.ID, body.Speakers)
+	if err != nil {
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			ctx.JSON(http.StatusNotFound, utils.NewMessageResponse("History or its transcript not found."))
+		case errors.Is(err, utils.ErrNoSpeakers), errors.Is(err, utils.ErrUnknownSpeaker):
+			ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse(err.Error()))
+		default:
+			slog.Error("Failed to rename speakers",
+				slog.String("action", "srt_speakers_rename"),
+				slog.String("history_id", historyID.Hex()),
+				slog.String("user_id", userData.ID.Hex()),
+				slog.String("error", err.Error()))
+			ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred while renaming speakers. Please try again later or contact support."))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, history)
+}
+
+func (sd *SRTDelivery) FindCues(ctx *gin.Context) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+
+	historyID, err := bson.ObjectIDFromHex(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid history ID."))
+		return
+	}
+
+	cues, err := sd.SRTUseCase.FindCues(historyID, userData.ID)
+	if err != nil {
+		sd.handleCueError(ctx, err, "srt_cues_find", historyID, userData.ID)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, cues)
+}
+
+func (sd *SRTDelivery) EditCues(ctx *gin.Context) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+
+	historyID, err := bson.ObjectIDFromHex(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid history ID."))
+		return
+	}
+
+	var body domain.CueEditBody
+	if err = ctx.ShouldBindJSON(&body); err != nil || len(body.Operations) == 0 {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid request body"))
+		return
+	}
+
+	if len(body.Operations) > domain.MaxCueOperations {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse(fmt.Sprintf("At most %d operations can be sent at once.", domain.MaxCueOperations)))
+		return
+	}
+
+	cues, err := sd.SRTUseCase.EditCues(historyID, userData.ID, body)
+	if err != nil {
+		sd.handleCueError(ctx, err, "srt_cues_edit", historyID, userData.ID)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, cues)
+}
+
+func (sd *SRTDelivery) UndoCueEdit(ctx *gin.Context) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+
+	historyID, err := bson.ObjectIDFromHex(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid history ID."))
+		return
+	}
+
+	var body domain.UndoCueEditBody
+	if err = ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid request body"))
+		return
+	}
+
+	cues, err := sd.SRTUseCase.UndoCueEdit(historyID, userData.ID, body.Revision)
+	if err != nil {
+		sd.handleCueError(ctx, err, "srt_cues_undo", historyID, userData.ID)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, cues)
+}
+
+func (sd *SRTDelivery) handleCueError(ctx *gin.Context, err error, action string, historyID, userID bson.ObjectID) {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		ctx.JSON(http.StatusNotFound, utils.NewMessageResponse("History or its transcript not found."))
+	case errors.Is(err, utils.ErrRevisionConflict):
+		ctx.JSON(http.StatusConflict, utils.NewMessageResponse("The subtitles were changed in the meantime. Reload them and try again."))
+	case errors.Is(err, utils.ErrNothingToUndo), errors.Is(err, utils.ErrInvalidCueEdit):
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse(err.Error()))
+	case errors.Is(err, utils.ErrSubtitlePublish):
+		slog.Error("Failed to publish edited subtitles",
+			slog.String("action", action),
+			slog.String("history_id", historyID.Hex()),
+			slog.String("user_id", userID.Hex()),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusServiceUnavailable, utils.NewMessageResponse("Your changes were not saved because the subtitle file could not be updated. Please try again."))
+	default:
+		slog.Error("Failed to process cue edit request",
+			slog.String("action", action),
+			slog.String("history_id", historyID.Hex()),
+			slog.String("user_id", userID.Hex()),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred while updating the subtitles. Please try again later or contact support."))
+	}
+}
+
+func (sd *SRTDelivery) EmbedSubtitles(ctx *gin.Context) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+
+	historyID, err := bson.ObjectIDFromHex(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid history ID."))
+		return
+	}
+
+	videoURL, err := sd.SRTUseCase.EmbedSubtitles(ctx.Request.Context(), historyID, userData.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			ctx.JSON(http.StatusNotFound, utils.NewMessageResponse("History or its transcript not found."))
+		case errors.Is(err, utils.ErrMediaNotMP4), errors.Is(err, utils.ErrUnsupportedMP4):
+			ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Subtitles can only be embedded into the original MP4 upload."))
+		default:
+			slog.Error("Failed to embed subtitles",
+				slog.String("action", "srt_embed"),
+				slog.String("history_id", historyID.Hex()),
+				slog.String("user_id", userData.ID.Hex()),
+				slog.String("error", err.Error()))
+			ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred while embedding the subtitles. Please try again later or contact support."))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"video_url": videoURL,
+	})
+}
+
+func (sd *SRTDelivery) FindJob(ctx *gin.Context) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+	fileID := ctx.Param("fileID")
+
+	job, err := sd.ConversionJobUseCase.FindOneByFileIDAndUserID(fileID, userData.ID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.JSON(http.StatusNotFound, utils.NewMessageResponse("Conversion job not found."))
+			return
+		}
+		slog.Error("Failed to lookup conversion job",
+			slog.String("action", "conversion_job_lookup"),
+			slog.String("file_id", fileID),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred while retrieving the conversion status. Please try again later or contact support."))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
+
+func (sd *SRTDelivery) StreamJobEvents(ctx *gin.Context) {
+	user, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("An error occurred. Please try again later or contact support."))
+		return
+	}
+
+	userData := user.(*domain.User)
+	fileID := ctx.Param("fileID")
+
+	// Subscribe before reading the job so no transition is lost in between.
+	events, err := sd.ConversionJobUseCase.SubscribeEvents(ctx.Request.Context(), fileID)
+	if err != nil {
+		slog.Error("Failed to subscribe to conversion job events",
+			slog.String("action", "conversion_job_subscribe"),
+			slog.String("file_id", fileID),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred while following the conversion. Please try again later or contact support."))
+		return
+	}
+
+	job, err := sd.ConversionJobUseCase.FindOneByFileIDAndUserID(fileID, userData.ID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.JSON(http.StatusNotFound, utils.NewMessageResponse("Conversion job not found."))
+			return
+		}
+		slog.Error("Failed to lookup conversion job",
+			slog.String("action", "conversion_job_lookup"),
+			slog.String("file_id", fileID),
+			slog.String("user_id", userData.ID.Hex()),
+			slog.String("error", err.Error()))
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("An error occurred while retrieving the conversion status. Please try again later or contact support."))
+		return
+	}
+
+	current := domain.JobEvent{
+		FileID:    job.FileID,
+		Status:    job.Status,
+		HistoryID: job.HistoryID,
+		Error:     job.Error,
+		Timestamp: job.UpdatedAt,
+	}
+
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+
+	ctx.SSEvent("status", current)
+	if current.IsFinal() {
+		return
+	}
+	// Send the current status right away instead of with the next event or heartbeat.
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(15 * time.Second)
+	defer heartbeat.Stop()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Request.Context().Done():
+			return false
+		case <-heartbeat.C:
+			ctx.SSEvent("ping", time.Now().UTC())
+			return true
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			ctx.SSEvent("status", event)
+			return !event.IsFinal()
+		}
+	})
 }
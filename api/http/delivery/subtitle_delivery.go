@@ -0,0 +1,123 @@
+package delivery
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
+	"github.com/kwa0x2/SmartSRT-Backend/utils"
+	"github.com/kwa0x2/SmartSRT-Backend/utils/subtitle"
+	"github.com/kwa0x2/SmartSRT-Backend/utils/validator"
+)
+
+const subtitleMaxSize int64 = 5 << 20
+
+type SubtitleDelivery struct {
+	SubtitleUseCase domain.SubtitleUseCase
+}
+
+func (sd *SubtitleDelivery) Convert(ctx *gin.Context) {
+	content, fileName, ok := readSubtitleFile(ctx)
+	if !ok {
+		return
+	}
+
+	from := subtitle.FormatFromFileName(fileName)
+	if !subtitle.IsParsableFormat(from) {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid file type. Only SRT, VTT, ASS and SBV files are allowed."))
+		return
+	}
+
+	to := types.SubtitleFormat(strings.ToLower(ctx.PostForm("format")))
+	if !subtitle.IsValidFormat(to) {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("format must be one of srt, vtt, ass, ttml, sbv or txt"))
+		return
+	}
+
+	body, contentType, err := sd.SubtitleUseCase.Convert(content, from, to)
+	if err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, utils.NewMessageResponse(fmt.Sprintf("Failed to parse subtitle file: %s", err.Error())))
+		return
+	}
+
+	outName := strings.TrimSuffix(fileName, filepath.Ext(fileName)) + subtitle.FileExtension(to)
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", outName))
+	ctx.Data(http.StatusOK, contentType+"; charset=utf-8", body)
+}
+
+func (sd *SubtitleDelivery) Lint(ctx *gin.Context) {
+	content, fileName, ok := readSubtitleFile(ctx)
+	if !ok {
+		return
+	}
+
+	format := subtitle.FormatFromFileName(fileName)
+	if !subtitle.IsLintableFormat(format) {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid file type. Only SRT and VTT files are allowed."))
+		return
+	}
+
+	opts, err := validator.ParseLintOptions(ctx.PostForm)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sd.SubtitleUseCase.Lint(content, format, *opts))
+}
+
+func (sd *SubtitleDelivery) AdjustTiming(ctx *gin.Context) {
+	content, fileName, ok := readSubtitleFile(ctx)
+	if !ok {
+		return
+	}
+
+	format := subtitle.FormatFromFileName(fileName)
+	if !subtitle.IsParsableFormat(format) {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("Invalid file type. Only SRT, VTT, ASS and SBV files are allowed."))
+		return
+	}
+
+	adjustment, err := validator.ParseTimingAdjustment(ctx.PostForm)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse(err.Error()))
+		return
+	}
+
+	body, contentType, err := sd.SubtitleUseCase.AdjustTiming(content, format, *adjustment)
+	if err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, utils.NewMessageResponse(fmt.Sprintf("Failed to parse subtitle file: %s", err.Error())))
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	ctx.Data(http.StatusOK, contentType+"; charset=utf-8", body)
+}
+
+// readSubtitleFile reads the "file" form field, writing the error response itself.
+func readSubtitleFile(ctx *gin.Context) ([]byte, string, bool) {
+	file, header, err := ctx.Request.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, utils.NewMessageResponse("No file uploaded. Please select a file."))
+		return nil, "", false
+	}
+	defer file.Close()
+
+	if header.Size > subtitleMaxSize {
+		ctx.JSON(http.StatusRequestEntityTooLarge, utils.NewMessageResponse("Subtitle file is too large. Maximum size is 5MB."))
+		return nil, "", false
+	}
+
+	content, err := io.ReadAll(io.LimitReader(file, subtitleMaxSize))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, utils.NewMessageResponse("Failed to process file. Please try again."))
+		return nil, "", false
+	}
+
+	return content, header.Filename, true
+}
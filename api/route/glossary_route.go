@@ -0,0 +1,30 @@
+package route
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/gin-gonic/gin"
+	"github.com/kwa0x2/SmartSRT-Backend/api/http/delivery"
+	"github.com/kwa0x2/SmartSRT-Backend/api/middleware"
+	"github.com/kwa0x2/SmartSRT-Backend/config"
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/repository"
+	"github.com/kwa0x2/SmartSRT-Backend/usecase"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func NewGlossaryRoute(env *config.Env, group *gin.RouterGroup, db *mongo.Database, dynamodb *dynamodb.Client) {
+	sr := repository.NewSessionRepository(dynamodb, domain.TableName)
+	seu := usecase.NewSessionUseCase(sr, repository.NewBaseRepository[*domain.User](db))
+
+	gd := &delivery.GlossaryDelivery{
+		GlossaryUseCase: usecase.NewGlossaryUseCase(repository.NewBaseRepository[*domain.Glossary](db)),
+	}
+
+	glossaryRoute := group.Group("/glossary")
+	{
+		glossaryRoute.GET("", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), gd.FindAll)
+		glossaryRoute.POST("", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), gd.Create)
+		glossaryRoute.PATCH("/:id", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), gd.Update)
+		glossaryRoute.DELETE("/:id", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), gd.Delete)
+	}
+}
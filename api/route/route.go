@@ -32,9 +32,11 @@ func Setup(env *config.Env, db *mongo.Database, dynamodb *dynamodb.Client, route
 
 	NewAuthRoute(env, groupRouter, db, dynamodb, resendClient, paddleSDK)
 	NewUserRoute(env, groupRouter, db, dynamodb)
-	NewSRTRoute(env, groupRouter, s3Client, lambdaClient, env.AWSS3BucketName, env.AWSLambdaFuncName, db, dynamodb)
+	NewSRTRoute(env, groupRouter, s3Client, lambdaClient, env.AWSS3BucketName, db, dynamodb)
 	NewUsageRoute(env, groupRouter, db, dynamodb)
 	NewContactRoute(env, groupRouter, db, resendClient)
 	NewPaddleRoutes(env, groupRouter, paddleSDK, db, dynamodb)
 	NewSubscriptionRoute(env, groupRouter, dynamodb, db)
+	NewSubtitleRoute(env, groupRouter, db, dynamodb)
+	NewGlossaryRoute(env, groupRouter, db, dynamodb)
 }
@@ -18,11 +18,11 @@ import (
 	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
-func NewSRTRoute(env *config.Env, group *gin.RouterGroup, s3Client *s3.Client, lambdaClient *lambda.Client, bucketName, lambdaFuncName string, db *mongo.Database, dynamodb *dynamodb.Client) {
+func NewSRTRoute(env *config.Env, group *gin.RouterGroup, s3Client *s3.Client, lambdaClient *lambda.Client, bucketName string, db *mongo.Database, dynamodb *dynamodb.Client) {
 	logger := slog.Default()
 
 	su := repository.NewSessionRepository(dynamodb, domain.TableName)
-	sr := repository.NewSRTRepository(s3Client, lambdaClient, db, bucketName, lambdaFuncName, domain.CollectionSRTHistory)
+	sr := repository.NewSRTRepository(s3Client, db, bucketName, domain.CollectionSRTHistory)
 	seu := usecase.NewSessionUseCase(su, repository.NewBaseRepository[*domain.User](db))
 
 	usguc := usecase.NewUsageUseCase(env, repository.NewBaseRepository[*domain.Usage](db), repository.NewBaseRepository[*domain.User](db))
@@ -35,14 +35,46 @@ func NewSRTRoute(env *config.Env, group *gin.RouterGroup, s3Client *s3.Client, l
 		os.Exit(1)
 	}
 
+	cju := usecase.NewConversionJobUseCase(repository.NewBaseRepository[*domain.ConversionJob](db), repository.NewJobEventRepository(rmq))
+
 	sd := &delivery.SRTDelivery{
-		SRTUseCase: usecase.NewSRTUseCase(sr, usguc, repository.NewBaseRepository[*domain.SRTHistory](db)),
-		RabbitMQ:   rmq,
+		SRTUseCase:           usecase.NewSRTUseCase(sr, bootstrap.NewTranscriber(env, lambdaClient), bootstrap.NewTranslator(env), usguc, cju, usecase.NewGlossaryUseCase(repository.NewBaseRepository[*domain.Glossary](db)), repository.NewBaseRepository[*domain.SRTHistory](db), repository.NewBaseRepository[*domain.Transcript](db), repository.NewBaseRepository[*domain.CueEdit](db)),
+		ConversionJobUseCase: cju,
+		UploadUseCase:        usecase.NewUploadUseCase(sr, repository.NewUploadChunkRepository(s3Client, bucketName), repository.NewBaseRepository[*domain.Upload](db)),
+		UsageUseCase:         usguc,
+		BatchUseCase:         usecase.NewBatchUseCase(sr, cju, repository.NewBaseRepository[*domain.Batch](db)),
+		RabbitMQ:             rmq,
 	}
 
 	srtRoute := group.Group("/srt")
 	{
 		srtRoute.POST("", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.ConvertFileToSRT)
 		srtRoute.GET("/histories", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.FindHistories)
+		srtRoute.GET("/histories/trash", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.FindDeletedHistories)
+		srtRoute.GET("/histories/:id/download", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.DownloadHistory)
+		srtRoute.PATCH("/histories/:id", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.RenameHistory)
+		srtRoute.DELETE("/histories/:id", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.DeleteHistory)
+		srtRoute.POST("/histories/:id/restore", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.RestoreHistory)
+		srtRoute.GET("/search", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.SearchTranscripts)
+		srtRoute.POST("/uploads", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.InitiateUpload)
+		srtRoute.OPTIONS("/uploads/tus", sd.TusOptions)
+		srtRoute.POST("/uploads/tus", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.TusCreate)
+		srtRoute.HEAD("/uploads/tus/:id", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.TusHead)
+		srtRoute.PATCH("/uploads/tus/:id", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.TusPatch)
+		srtRoute.POST("/uploads/:id/complete", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.CompleteUpload)
+		srtRoute.GET("/histories/:id/transcript", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.FindTranscript)
+		srtRoute.POST("/histories/:id/render", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.RenderHistory)
+		srtRoute.POST("/histories/:id/timing", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.AdjustHistoryTiming)
+		srtRoute.POST("/histories/:id/translate", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.TranslateHistory)
+		srtRoute.PATCH("/histories/:id/speakers", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.RenameSpeakers)
+		srtRoute.GET("/histories/:id/cues", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.FindCues)
+		srtRoute.PATCH("/histories/:id/cues", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.EditCues)
+		srtRoute.POST("/histories/:id/cues/undo", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.UndoCueEdit)
+		srtRoute.POST("/histories/:id/embed", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.EmbedSubtitles)
+		srtRoute.POST("/batches", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.CreateBatch)
+		srtRoute.GET("/batches/:batchID", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.FindBatch)
+		srtRoute.GET("/batches/:batchID/download", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.DownloadBatch)
+		srtRoute.GET("/jobs/:fileID", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.FindJob)
+		srtRoute.GET("/jobs/:fileID/events", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.StreamJobEvents)
 	}
 }
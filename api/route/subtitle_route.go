@@ -0,0 +1,29 @@
+package route
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/gin-gonic/gin"
+	"github.com/kwa0x2/SmartSRT-Backend/api/http/delivery"
+	"github.com/kwa0x2/SmartSRT-Backend/api/middleware"
+	"github.com/kwa0x2/SmartSRT-Backend/config"
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/repository"
+	"github.com/kwa0x2/SmartSRT-Backend/usecase"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func NewSubtitleRoute(env *config.Env, group *gin.RouterGroup, db *mongo.Database, dynamodb *dynamodb.Client) {
+	sr := repository.NewSessionRepository(dynamodb, domain.TableName)
+	seu := usecase.NewSessionUseCase(sr, repository.NewBaseRepository[*domain.User](db))
+
+	sd := &delivery.SubtitleDelivery{
+		SubtitleUseCase: usecase.NewSubtitleUseCase(),
+	}
+
+	subtitleRoute := group.Group("/subtitles")
+	{
+		subtitleRoute.POST("/convert", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.Convert)
+		subtitleRoute.POST("/lint", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.Lint)
+		subtitleRoute.POST("/timing", middleware.SessionMiddleware(seu, repository.NewBaseRepository[*domain.User](db), repository.NewBaseRepository[*domain.Usage](db), env), sd.AdjustTiming)
+	}
+}
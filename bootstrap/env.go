@@ -24,6 +24,10 @@ func NewEnv() *config.Env {
 	}
 
 	viper.AutomaticEnv()
+	viper.SetDefault("TRANSCRIBER", "lambda")
+	viper.SetDefault("TRANSCRIBER_HTTP_URL", "")
+	viper.SetDefault("TRANSLATOR", "")
+	viper.SetDefault("TRANSLATOR_HTTP_URL", "")
 
 	if err := viper.Unmarshal(&env); err != nil {
 		logger.Error("Environment could not be parseds",
@@ -117,7 +117,7 @@ func ConnectMongoDB(env *config.Env) *mongo.Database {
 	}
 
 	// Run seeder after successful connection
-	s := seeder.NewSeeder(database)
+	s := seeder.NewSeeder(database, env.AWSS3BucketName)
 	if err := s.SeedDatabase(); err != nil {
 		logger.Warn("Database seeding failed",
 			slog.String("error", err.Error()),
@@ -0,0 +1,31 @@
+package bootstrap
+
+import (
+	"log/slog"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/kwa0x2/SmartSRT-Backend/config"
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
+	"github.com/kwa0x2/SmartSRT-Backend/repository"
+)
+
+func NewTranscriber(env *config.Env, lambdaClient *lambda.Client) domain.Transcriber {
+	logger := slog.Default()
+
+	var transcriber domain.Transcriber
+	switch types.TranscriberType(env.Transcriber) {
+	case types.HTTPTranscriber:
+		transcriber = repository.NewHTTPTranscriber(env.TranscriberHTTPURL)
+	case types.StubTranscriber:
+		transcriber = repository.NewStubTranscriber()
+	default:
+		transcriber = repository.NewLambdaTranscriber(lambdaClient, env.AWSLambdaFuncName)
+	}
+
+	logger.Info("Transcriber selected",
+		slog.String("transcriber", env.Transcriber),
+	)
+
+	return transcriber
+}
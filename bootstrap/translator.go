@@ -0,0 +1,47 @@
+package bootstrap
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/kwa0x2/SmartSRT-Backend/config"
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
+	"github.com/kwa0x2/SmartSRT-Backend/repository"
+)
+
+// NewTranslator returns the configured translator, or nil when TRANSLATOR is unset; the
+// translate endpoint then reports that translation is not available.
+func NewTranslator(env *config.Env) domain.Translator {
+	logger := slog.Default()
+
+	var translator domain.Translator
+	switch types.TranslatorType(env.Translator) {
+	case "":
+		logger.Info("No translator configured, translation is disabled")
+		return nil
+	case types.HTTPTranslator:
+		translator = repository.NewHTTPTranslator(env.TranslatorHTTPURL)
+	case types.FakeTranslator:
+		// The fake translator only tags text with the target language; in production it
+		// would silently serve untranslated subtitles.
+		if env.AppEnv == "production" {
+			logger.Error("Fake translator is not allowed in production",
+				slog.String("translator", env.Translator),
+			)
+			os.Exit(1)
+		}
+		translator = repository.NewFakeTranslator()
+	default:
+		logger.Error("Unknown translator",
+			slog.String("translator", env.Translator),
+		)
+		os.Exit(1)
+	}
+
+	logger.Info("Translator selected",
+		slog.String("translator", env.Translator),
+	)
+
+	return translator
+}
@@ -1,10 +1,10 @@
 package main
 
 import (
-	"bytes"
 	"log/slog"
 	"mime/multipart"
 	"os"
+	"time"
 
 	"github.com/kwa0x2/SmartSRT-Backend/bootstrap"
 	"github.com/kwa0x2/SmartSRT-Backend/config"
@@ -12,31 +12,41 @@ import (
 	"github.com/kwa0x2/SmartSRT-Backend/rabbitmq"
 	"github.com/kwa0x2/SmartSRT-Backend/repository"
 	"github.com/kwa0x2/SmartSRT-Backend/usecase"
+	"github.com/kwa0x2/SmartSRT-Backend/utils"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
-type fileReader struct {
-	*bytes.Reader
-}
+// emailDownloadURLExpiry bounds how long the link in the "subtitle ready" email works;
+// after that the subtitle is downloaded from the history page.
+const emailDownloadURLExpiry = 24 * time.Hour
 
-func (f *fileReader) Close() error {
-	return nil
-}
+// expiredUploadSweepInterval is how often abandoned uploads are discarded.
+const expiredUploadSweepInterval = 15 * time.Minute
+
+// deletedHistoryPurgeInterval is how often histories past their trash retention are removed.
+const deletedHistoryPurgeInterval = time.Hour
 
 type Consumer struct {
-	env           *config.Env
-	logger        *slog.Logger
-	SRTUseCase    domain.SRTUseCase
-	resendUseCase domain.ResendUseCase
-	rabbitMQ      *domain.RabbitMQ
+	env            *config.Env
+	logger         *slog.Logger
+	instanceID     string
+	SRTUseCase     domain.SRTUseCase
+	uploadUseCase  domain.UploadUseCase
+	resendUseCase  domain.ResendUseCase
+	lockRepository domain.LockRepository
+	rabbitMQ       *domain.RabbitMQ
 }
 
-func NewConsumer(env *config.Env, logger *slog.Logger, SRTUseCase domain.SRTUseCase, ResendUseCase domain.ResendUseCase, rabbitMQ *domain.RabbitMQ) *Consumer {
+func NewConsumer(env *config.Env, logger *slog.Logger, SRTUseCase domain.SRTUseCase, UploadUseCase domain.UploadUseCase, ResendUseCase domain.ResendUseCase, lockRepository domain.LockRepository, rabbitMQ *domain.RabbitMQ) *Consumer {
 	return &Consumer{
-		env:           env,
-		logger:        logger,
-		SRTUseCase:    SRTUseCase,
-		resendUseCase: ResendUseCase,
-		rabbitMQ:      rabbitMQ,
+		env:            env,
+		logger:         logger,
+		instanceID:     utils.GenerateUUID(),
+		SRTUseCase:     SRTUseCase,
+		uploadUseCase:  UploadUseCase,
+		resendUseCase:  ResendUseCase,
+		lockRepository: lockRepository,
+		rabbitMQ:       rabbitMQ,
 	}
 }
 
@@ -46,17 +56,24 @@ func (c *Consumer) Start() error {
 			slog.String("file_id", msg.FileID),
 			slog.String("user_id", msg.UserID.Hex()),
 			slog.String("file_name", msg.FileName),
+			slog.String("stored_file_name", msg.StoredFileName),
 			slog.Int64("file_size", msg.FileSize),
 			slog.Float64("file_duration", msg.FileDuration),
 		)
 
+		// The API has already staged the media in S3, so only the stored object name travels with the message.
 		request := domain.FileConversionRequest{
 			UserID:              msg.UserID,
+			FileID:              msg.FileID,
 			WordsPerLine:        msg.WordsPerLine,
 			Punctuation:         msg.Punctuation,
 			ConsiderPunctuation: msg.ConsiderPunctuation,
-			FileName:            msg.FileName,
-			File:                &fileReader{bytes.NewReader(msg.FileContent)},
+			Format:              msg.Format,
+			LineBreak:           msg.LineBreak,
+			Language:            msg.Language,
+			Diarization:         msg.Diarization,
+			TextProcessing:      msg.TextProcessing,
+			FileName:            msg.StoredFileName,
 			FileHeader: multipart.FileHeader{
 				Filename: msg.FileName,
 				Size:     msg.FileSize,
@@ -64,13 +81,24 @@ func (c *Consumer) Start() error {
 			FileDuration: msg.FileDuration,
 		}
 
-		response, err := c.SRTUseCase.UploadFileAndConvertToSRT(request)
+		response, err := c.SRTUseCase.ConvertToSRT(request)
 		if err != nil {
 			return nil, err
 		}
 
+		// Segments are persisted with the history; keep the RPC reply small.
+		response.Body.Segments = nil
+
 		go func() {
-			if _, err := c.resendUseCase.SendSRTCreatedEmail(msg.Email, response.Body.SRTURL); err != nil {
+			historyID, err := bson.ObjectIDFromHex(response.HistoryID)
+			var downloadURL string
+			if err == nil {
+				downloadURL, err = c.SRTUseCase.CreateDownloadURL(historyID, msg.UserID, emailDownloadURLExpiry)
+			}
+			if err == nil {
+				_, err = c.resendUseCase.SendSRTCreatedEmail(msg.Email, downloadURL)
+			}
+			if err != nil {
 				c.logger.Error("Email sending failed",
 					slog.String("email", msg.Email),
 					slog.String("file_id", msg.FileID),
@@ -80,7 +108,7 @@ func (c *Consumer) Start() error {
 				c.logger.Info("Email sent successfully",
 					slog.String("email", msg.Email),
 					slog.String("file_id", msg.FileID),
-					slog.String("srt_url", response.Body.SRTURL),
+					slog.String("history_id", response.HistoryID),
 				)
 			}
 		}()
@@ -88,7 +116,7 @@ func (c *Consumer) Start() error {
 		c.logger.Info("File processed successfully",
 			slog.String("file_id", msg.FileID),
 			slog.String("user_id", msg.UserID.Hex()),
-			slog.String("srt_url", response.Body.SRTURL),
+			slog.String("history_id", response.HistoryID),
 		)
 		return response, nil
 	})
@@ -100,12 +128,67 @@ func (c *Consumer) Start() error {
 		return err
 	}
 
+	go c.runLeased("deleted_history_purge", deletedHistoryPurgeInterval, c.purgeDeletedHistories)
+	go c.runLeased("expired_upload_sweep", expiredUploadSweepInterval, c.purgeExpiredUploads)
+
 	c.logger.Info("Consumer started successfully",
 		slog.String("status", "waiting_for_messages"),
 	)
 	select {}
 }
 
+// purgeDeletedHistories removes histories whose trash retention has expired.
+func (c *Consumer) purgeDeletedHistories() {
+	purged, err := c.SRTUseCase.PurgeDeletedHistories()
+	if err != nil {
+		c.logger.Error("Deleted history purge failed",
+			slog.Int("purged", purged),
+			slog.String("error", err.Error()),
+		)
+	} else if purged > 0 {
+		c.logger.Info("Deleted histories purged",
+			slog.Int("purged", purged),
+		)
+	}
+}
+
+// runLeased runs task at startup and then every interval, but only in the replica that
+// holds the named lease, so consumer replicas do not repeat the same cleanup.
+func (c *Consumer) runLeased(name string, interval time.Duration, task func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// The lease outlives one interval, so the holder renews it before anyone can take over.
+		acquired, err := c.lockRepository.Acquire(name, c.instanceID, interval+interval/2)
+		if err != nil {
+			c.logger.Error("Lease acquisition failed",
+				slog.String("lease", name),
+				slog.String("error", err.Error()),
+			)
+		} else if acquired {
+			task()
+		}
+
+		<-ticker.C
+	}
+}
+
+// purgeExpiredUploads discards uploads that were started but never completed.
+func (c *Consumer) purgeExpiredUploads() {
+	purged, err := c.uploadUseCase.PurgeExpiredUploads()
+	if err != nil {
+		c.logger.Error("Expired upload purge failed",
+			slog.Int("purged", purged),
+			slog.String("error", err.Error()),
+		)
+	} else if purged > 0 {
+		c.logger.Info("Expired uploads purged",
+			slog.Int("purged", purged),
+		)
+	}
+}
+
 func main() {
 	app := bootstrap.App()
 	env := app.Env
@@ -128,12 +211,14 @@ func main() {
 		slog.String("status", "connected"),
 	)
 
-	sr := repository.NewSRTRepository(s3Client, lambdaClient, db, env.AWSS3BucketName, env.AWSLambdaFuncName, domain.CollectionSRTHistory)
+	sr := repository.NewSRTRepository(s3Client, db, env.AWSS3BucketName, domain.CollectionSRTHistory)
 	usguc := usecase.NewUsageUseCase(env, repository.NewBaseRepository[*domain.Usage](db), repository.NewBaseRepository[*domain.User](db))
-	srtUseCase := usecase.NewSRTUseCase(sr, usguc, repository.NewBaseRepository[*domain.SRTHistory](db))
+	cju := usecase.NewConversionJobUseCase(repository.NewBaseRepository[*domain.ConversionJob](db), repository.NewJobEventRepository(rabbitMQ))
+	srtUseCase := usecase.NewSRTUseCase(sr, bootstrap.NewTranscriber(env, lambdaClient), bootstrap.NewTranslator(env), usguc, cju, usecase.NewGlossaryUseCase(repository.NewBaseRepository[*domain.Glossary](db)), repository.NewBaseRepository[*domain.SRTHistory](db), repository.NewBaseRepository[*domain.Transcript](db), repository.NewBaseRepository[*domain.CueEdit](db))
+	uploadUseCase := usecase.NewUploadUseCase(sr, repository.NewUploadChunkRepository(s3Client, env.AWSS3BucketName), repository.NewBaseRepository[*domain.Upload](db))
 	resendUseCase := usecase.NewResendUseCase(repository.NewResendRepository(app.ResendClient))
 
-	consumer := NewConsumer(env, logger, srtUseCase, resendUseCase, rabbitMQ)
+	consumer := NewConsumer(env, logger, srtUseCase, uploadUseCase, resendUseCase, repository.NewLockRepository(db), rabbitMQ)
 	if err = consumer.Start(); err != nil {
 		logger.Error("Consumer error",
 			slog.String("error", err.Error()),
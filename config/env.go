@@ -18,7 +18,11 @@ type Env struct {
 	AWSAccessKeyID         string `mapstructure:"AWS_ACCESS_KEY_ID" validate:"required"`
 	AWSSecretAccessKey     string `mapstructure:"AWS_SECRET_ACCESS_KEY" validate:"required"`
 	AWSS3BucketName        string `mapstructure:"AWS_S3_BUCKET_NAME" validate:"required"`
-	AWSLambdaFuncName      string `mapstructure:"AWS_LAMBDA_FUNC_NAME" validate:"required"`
+	AWSLambdaFuncName      string `mapstructure:"AWS_LAMBDA_FUNC_NAME" validate:"required_if=Transcriber lambda"`
+	Transcriber            string `mapstructure:"TRANSCRIBER" validate:"oneof=lambda http stub"`
+	TranscriberHTTPURL     string `mapstructure:"TRANSCRIBER_HTTP_URL" validate:"required_if=Transcriber http,omitempty,url"`
+	Translator             string `mapstructure:"TRANSLATOR" validate:"omitempty,oneof=http fake"`
+	TranslatorHTTPURL      string `mapstructure:"TRANSLATOR_HTTP_URL" validate:"required_if=Translator http,omitempty,url"`
 	SinchAppKey            string `mapstructure:"SINCH_APP_KEY" validate:"required"`
 	SinchAppSecret         string `mapstructure:"SINCH_APP_SECRET" validate:"required"`
 	ResendApiKey           string `mapstructure:"RESEND_API_KEY" validate:"required"`
@@ -18,6 +18,26 @@ type BaseRepository[T Entity] interface {
 	FindOne(ctx context.Context, filter bson.D) (T, error)
 	Find(ctx context.Context, filter bson.D, opts *options.FindOptionsBuilder) ([]T, error)
 	UpdateOne(ctx context.Context, filter bson.D, update bson.D, opts *options.UpdateOneOptionsBuilder) error
+	UpdateOneModified(ctx context.Context, filter bson.D, update bson.D) (bool, error)
 	SoftDelete(ctx context.Context, filter bson.D) error
+	Count(ctx context.Context, filter bson.D) (int64, error)
+	FindPage(ctx context.Context, filter bson.D, page PageRequest) (*Page[T], error)
 	GetDatabase() *mongo.Database
 }
+
+// PageRequest selects one page of a keyset-paginated query ordered by SortField, with
+// _id breaking ties. Cursor is the NextCursor of the previous page, empty for the first.
+type PageRequest struct {
+	SortField  string
+	Descending bool
+	Limit      int64
+	Cursor     string
+}
+
+// Page is one page of results. NextCursor is empty on the last page; Total is filled in
+// by callers that also count the matching documents.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      int64  `json:"total"`
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"io"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	CollectionBatch = "batches"
+
+	MaxBatchFiles = 50
+)
+
+// Batch groups conversion jobs submitted in one request. Its status is derived from
+// the jobs carrying its BatchID rather than stored.
+type Batch struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"-"`
+	BatchID   string        `bson:"batch_id" json:"batch_id" validate:"required"`
+	UserID    bson.ObjectID `bson:"user_id" json:"-" validate:"required"`
+	FileCount int           `bson:"file_count" json:"file_count" validate:"required,min=1"`
+	Duration  float64       `bson:"duration" json:"duration"`
+	CreatedAt time.Time     `bson:"created_at" json:"created_at" validate:"required"`
+	UpdatedAt time.Time     `bson:"updated_at" json:"updated_at" validate:"required"`
+	DeletedAt *time.Time    `bson:"deleted_at,omitempty" json:"-"`
+}
+
+func (b *Batch) Validate() error {
+	validate := validator.New()
+	return validate.Struct(b)
+}
+
+func (b *Batch) GetCollectionName() string {
+	return CollectionBatch
+}
+
+func (b *Batch) SetID(id bson.ObjectID) {
+	b.ID = id
+}
+
+type BatchStatusResponse struct {
+	*Batch
+	Status    types.BatchStatus `json:"status"`
+	Completed int               `json:"completed"`
+	Failed    int               `json:"failed"`
+	Pending   int               `json:"pending"`
+	Jobs      []*ConversionJob  `json:"jobs"`
+}
+
+type BatchUseCase interface {
+	Create(batch *Batch) error
+	FindStatus(batchID string, userID bson.ObjectID) (*BatchStatusResponse, error)
+	WriteArchive(jobs []*ConversionJob, w io.Writer) error
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	CollectionConversionJob = "conversion_jobs"
+)
+
+type ConversionJob struct {
+	ID          bson.ObjectID   `bson:"_id,omitempty" json:"-"`
+	FileID      string          `bson:"file_id" json:"file_id" validate:"required"`
+	UserID      bson.ObjectID   `bson:"user_id" json:"-" validate:"required"`
+	BatchID     string          `bson:"batch_id,omitempty" json:"batch_id,omitempty"`
+	FileName    string          `bson:"file_name" json:"file_name" validate:"required"`
+	Duration    float64         `bson:"duration" json:"duration"`
+	Status      types.JobStatus `bson:"status" json:"status" validate:"required"`
+	HistoryID   string          `bson:"history_id,omitempty" json:"history_id,omitempty"`
+	SubtitleKey string          `bson:"subtitle_key,omitempty" json:"-"`
+	Error       string          `bson:"error,omitempty" json:"error,omitempty"`
+	CreatedAt   time.Time       `bson:"created_at" json:"created_at" validate:"required"`
+	UpdatedAt   time.Time       `bson:"updated_at" json:"updated_at" validate:"required"`
+	DeletedAt   *time.Time      `bson:"deleted_at,omitempty" json:"-"`
+}
+
+func (c *ConversionJob) Validate() error {
+	validate := validator.New()
+	return validate.Struct(c)
+}
+
+func (c *ConversionJob) GetCollectionName() string {
+	return CollectionConversionJob
+}
+
+func (c *ConversionJob) SetID(id bson.ObjectID) {
+	c.ID = id
+}
+
+type JobEvent struct {
+	FileID    string          `json:"file_id"`
+	Status    types.JobStatus `json:"status"`
+	HistoryID string          `json:"history_id,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+func (e *JobEvent) IsFinal() bool {
+	return e.Status == types.JobCompleted || e.Status == types.JobFailed
+}
+
+type JobEventRepository interface {
+	Publish(event JobEvent) error
+	Subscribe(ctx context.Context, fileID string) (<-chan JobEvent, error)
+}
+
+type ConversionJobUseCase interface {
+	Create(job *ConversionJob) error
+	UpdateStatus(fileID string, status types.JobStatus) error
+	Complete(fileID, historyID, subtitleKey string) error
+	Fail(fileID, reason string) error
+	FindOneByFileIDAndUserID(fileID string, userID bson.ObjectID) (*ConversionJob, error)
+	FindByBatchIDAndUserID(batchID string, userID bson.ObjectID) ([]*ConversionJob, error)
+	DeleteByBatchID(batchID string, userID bson.ObjectID) error
+	SubscribeEvents(ctx context.Context, fileID string) (<-chan JobEvent, error)
+}
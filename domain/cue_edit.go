@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	CollectionCueEdit = "cue_edits"
+
+	MaxCueOperations = 100
+)
+
+// CueOperation is a single editor change addressed by cue index. Indexes refer to the
+// cue list as left by the previous operation of the same request.
+//
+//   - text: replaces the text of cue Index with Text.
+//   - timing: moves Start and/or End of cue Index.
+//   - split: cuts cue Index at time At; the text is cut at rune Position, or at the word
+//     boundary closest to the same proportion of the text when Position is omitted.
+//   - merge: joins cue Index with the cue after it.
+type CueOperation struct {
+	Op       types.CueOperationType `bson:"op" json:"op"`
+	Index    int                    `bson:"index" json:"index"`
+	Text     *string                `bson:"text,omitempty" json:"text,omitempty"`
+	Start    *float64               `bson:"start,omitempty" json:"start,omitempty"`
+	End      *float64               `bson:"end,omitempty" json:"end,omitempty"`
+	At       *float64               `bson:"at,omitempty" json:"at,omitempty"`
+	Position *int                   `bson:"position,omitempty" json:"position,omitempty"`
+}
+
+// CueEdit records one accepted edit request. Before holds the cues it replaced, which is
+// what an undo restores.
+type CueEdit struct {
+	ID         bson.ObjectID   `bson:"_id,omitempty" json:"id"`
+	HistoryID  bson.ObjectID   `bson:"history_id" json:"-" validate:"required"`
+	UserID     bson.ObjectID   `bson:"user_id" json:"-" validate:"required"`
+	Revision   int             `bson:"revision" json:"revision" validate:"required"` // transcript revision this edit produced
+	Operations []CueOperation  `bson:"operations" json:"operations"`
+	Before     []TranscriptCue `bson:"before" json:"-"`
+	UndoneAt   *time.Time      `bson:"undone_at,omitempty" json:"undone_at,omitempty"`
+	CreatedAt  time.Time       `bson:"created_at" json:"created_at" validate:"required"`
+	UpdatedAt  time.Time       `bson:"updated_at" json:"updated_at" validate:"required"`
+	DeletedAt  *time.Time      `bson:"deleted_at,omitempty" json:"-"`
+}
+
+func (c *CueEdit) Validate() error {
+	validate := validator.New()
+	return validate.Struct(c)
+}
+
+func (c *CueEdit) GetCollectionName() string {
+	return CollectionCueEdit
+}
+
+func (c *CueEdit) SetID(id bson.ObjectID) {
+	c.ID = id
+}
+
+type CueEditBody struct {
+	Revision   int            `json:"revision"`
+	Operations []CueOperation `json:"operations"`
+}
+
+type UndoCueEditBody struct {
+	Revision int `json:"revision"`
+}
+
+// CueList is the editor view of a history: its current cues, the revision to send back
+// with the next edit and the edits made so far, newest first.
+type CueList struct {
+	HistoryID bson.ObjectID   `json:"history_id"`
+	Revision  int             `json:"revision"`
+	Cues      []TranscriptCue `json:"cues"`
+	Edits     []*CueEdit      `json:"edits"`
+}
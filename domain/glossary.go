@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	CollectionGlossary = "glossaries"
+
+	MaxGlossaryEntries = 500
+)
+
+// Glossary is one custom vocabulary term of a user. Term is sent to the transcriber as
+// a hint and every alias found in a transcript is replaced with it.
+type Glossary struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    bson.ObjectID `bson:"user_id" json:"-" validate:"required"`
+	Term      string        `bson:"term" json:"term" validate:"required,max=100"`
+	Aliases   []string      `bson:"aliases" json:"aliases" validate:"max=20,dive,required,max=100"`
+	CreatedAt time.Time     `bson:"created_at" json:"created_at" validate:"required"`
+	UpdatedAt time.Time     `bson:"updated_at" json:"updated_at" validate:"required"`
+	DeletedAt *time.Time    `bson:"deleted_at,omitempty" json:"-"`
+}
+
+func (g *Glossary) Validate() error {
+	validate := validator.New()
+	return validate.Struct(g)
+}
+
+func (g *Glossary) GetCollectionName() string {
+	return CollectionGlossary
+}
+
+func (g *Glossary) SetID(id bson.ObjectID) {
+	g.ID = id
+}
+
+type GlossaryBody struct {
+	Term    string   `json:"term"`
+	Aliases []string `json:"aliases"`
+}
+
+type GlossaryUseCase interface {
+	Create(glossary *Glossary) error
+	FindByUserID(userID bson.ObjectID) ([]*Glossary, error)
+	Update(id, userID bson.ObjectID, body GlossaryBody) (*Glossary, error)
+	Delete(id, userID bson.ObjectID) error
+}
@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+const (
+	CollectionLock = "locks"
+)
+
+// LockRepository hands out named leases so periodic jobs run in one consumer replica at
+// a time. The holder renews its lease by acquiring it again; a lease that is not renewed
+// expires and another replica takes over.
+type LockRepository interface {
+	Acquire(name, owner string, ttl time.Duration) (bool, error)
+}
@@ -5,12 +5,14 @@ import (
 	"sync"
 	"time"
 
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
 	amqp "github.com/rabbitmq/amqp091-go"
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 const (
-	QueueConversions = "srt_conversions"
+	QueueConversions  = "srt_conversions"
+	ExchangeJobEvents = "srt_job_events"
 
 	ReconnectDelay  = 5 * time.Second
 	ReInitDelay     = 2 * time.Second
@@ -52,16 +54,21 @@ func (p *ChannelPool) Close() {
 }
 
 type ConversionMessage struct {
-	UserID              bson.ObjectID `json:"user_id"`
-	WordsPerLine        int           `json:"words_per_line"`
-	Punctuation         bool          `json:"punctuation"`
-	ConsiderPunctuation bool          `json:"consider_punctuation"`
-	FileName            string        `json:"file_name"`
-	FileID              string        `json:"file_id"`
-	FileContent         []byte        `json:"file_content"`
-	FileSize            int64         `json:"file_size"`
-	FileDuration        float64       `json:"file_duration"`
-	Email               string        `json:"email"`
+	UserID              bson.ObjectID         `json:"user_id"`
+	WordsPerLine        int                   `json:"words_per_line"`
+	Punctuation         bool                  `json:"punctuation"`
+	ConsiderPunctuation bool                  `json:"consider_punctuation"`
+	Format              types.SubtitleFormat  `json:"format"`
+	LineBreak           LineBreakSettings     `json:"line_break"`
+	Language            string                `json:"language"`
+	Diarization         bool                  `json:"diarization"`
+	TextProcessing      TextProcessingOptions `json:"text_processing"`
+	FileName            string                `json:"file_name"`
+	FileID              string                `json:"file_id"`
+	StoredFileName      string                `json:"stored_file_name"`
+	FileSize            int64                 `json:"file_size"`
+	FileDuration        float64               `json:"file_duration"`
+	Email               string                `json:"email"`
 }
 
 type RabbitMQ struct {
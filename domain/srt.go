@@ -1,50 +1,139 @@
 package domain
 
 import (
+	"context"
+	"io"
 	"mime/multipart"
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 type LambdaBodyResponse struct {
-	Message string `json:"message"`
-	SRTURL  string `json:"srt_url"`
+	Message  string              `json:"message"`
+	SRTURL   string              `json:"srt_url,omitempty"`  // subtitle written by the transcriber; cleared before replying to clients
+	Language string              `json:"language,omitempty"` // detected (or requested) language, ISO 639-1
+	Segments []TranscriptSegment `json:"segments,omitempty"`
 }
 
 type LambdaResponse struct {
 	StatusCode int                `json:"status_code"`
 	Body       LambdaBodyResponse `json:"body"`
+	FileID     string             `json:"file_id,omitempty"`
+	HistoryID  string             `json:"history_id,omitempty"`
 }
 
 type FileConversionRequest struct {
-	UserID              bson.ObjectID `json:"user_id"`
-	WordsPerLine        int           `json:"words_per_line"`
-	Punctuation         bool          `json:"punctuation"`
-	ConsiderPunctuation bool          `json:"consider_punctuation"`
-	FileName            string        `json:"file_name"`
+	UserID              bson.ObjectID         `json:"user_id"`
+	FileID              string                `json:"file_id"`
+	WordsPerLine        int                   `json:"words_per_line"`
+	Punctuation         bool                  `json:"punctuation"`
+	ConsiderPunctuation bool                  `json:"consider_punctuation"`
+	Format              types.SubtitleFormat  `json:"format"`
+	LineBreak           LineBreakSettings     `json:"line_break"`
+	Language            string                `json:"language"` // ISO 639-1 or "auto"
+	Diarization         bool                  `json:"diarization"`
+	Vocabulary          []string              `json:"vocabulary,omitempty"` // glossary terms passed as transcription hints
+	TextProcessing      TextProcessingOptions `json:"text_processing"`
+	FileName            string                `json:"file_name"`
+	MediaURL            string                `json:"media_url,omitempty"` // short-lived download link for backends without bucket access
 	File                multipart.File
 	FileHeader          multipart.FileHeader
 	FileDuration        float64
 }
 
+// TextProcessingOptions select the post-processing applied to transcript segments
+// before rendering. Number normalization and profanity masking only apply to English.
+type TextProcessingOptions struct {
+	MaskProfanity    bool `bson:"mask_profanity" json:"mask_profanity"`
+	NormalizeNumbers bool `bson:"normalize_numbers" json:"normalize_numbers"`
+	RemoveFillers    bool `bson:"remove_fillers" json:"remove_fillers"`
+	SentenceCase     bool `bson:"sentence_case" json:"sentence_case"`
+}
+
+type RenameHistoryBody struct {
+	FileName string `json:"file_name"`
+}
+
+type RenameSpeakersBody struct {
+	Speakers map[string]string `json:"speakers"`
+}
+
+// LineBreakSettings select how words are grouped into cues. In words mode only
+// WordsPerLine applies; chars mode follows broadcast limits such as 42 characters over
+// 2 lines. Durations are in seconds and zero values mean no limit.
+type LineBreakSettings struct {
+	Mode            types.LineBreakMode `bson:"mode" json:"mode"`
+	MaxCharsPerLine int                 `bson:"max_chars_per_line,omitempty" json:"max_chars_per_line,omitempty"`
+	MaxLinesPerCue  int                 `bson:"max_lines_per_cue,omitempty" json:"max_lines_per_cue,omitempty"`
+	MinCueDuration  float64             `bson:"min_cue_duration,omitempty" json:"min_cue_duration,omitempty"`
+	MaxCueDuration  float64             `bson:"max_cue_duration,omitempty" json:"max_cue_duration,omitempty"`
+	MaxCPS          float64             `bson:"max_cps,omitempty" json:"max_cps,omitempty"`
+}
+
+// RenderRequest re-renders a stored transcript with new line settings.
+type RenderRequest struct {
+	HistoryID           bson.ObjectID
+	UserID              bson.ObjectID
+	WordsPerLine        int
+	Punctuation         bool
+	ConsiderPunctuation bool
+	Format              types.SubtitleFormat
+	LineBreak           LineBreakSettings
+}
+
+// HistoryQuery filters and pages a user's histories. To is exclusive, durations are in
+// seconds and FileName matches any part of the name regardless of case. Page only
+// applies when Paginated is set; otherwise every match is returned in Page's order.
+type HistoryQuery struct {
+	From        *time.Time
+	To          *time.Time
+	FileName    string
+	MinDuration *float64
+	MaxDuration *float64
+	Format      types.SubtitleFormat
+	Language    string
+	Page        PageRequest
+	Paginated   bool
+}
+
 const (
 	CollectionSRTHistory = "srt_history"
+	// CollectionCounter holds sequence counters, such as the version numbers of a history.
+	CollectionCounter = "counters"
+
+	// DownloadURLExpiry is how long a signed subtitle download link stays valid.
+	DownloadURLExpiry = 15 * time.Minute
+
+	// HistoryTrashRetention is how long a deleted history can be restored before it and
+	// its stored files are purged.
+	HistoryTrashRetention = 30 * 24 * time.Hour
 )
 
 type SRTHistory struct {
-	ID                  bson.ObjectID `bson:"_id,omitempty"`
-	UserID              bson.ObjectID `bson:"user_id" validate:"required"`
-	FileName            string        `bson:"file_name" validate:"required"`
-	S3URL               string        `bson:"s3_url" validate:"required"`
-	Duration            float64       `bson:"duration"`
-	WordsPerLine        int           `bson:"words_per_line"`
-	Punctuation         bool          `bson:"punctuation"`
-	ConsiderPunctuation bool          `bson:"consider_punctuation"`
-	CreatedAt           time.Time     `bson:"created_at"  validate:"required"`
-	UpdatedAt           time.Time     `bson:"updated_at"  validate:"required"`
-	DeletedAt           *time.Time    `bson:"deleted_at,omitempty"`
+	ID                  bson.ObjectID         `bson:"_id,omitempty"`
+	UserID              bson.ObjectID         `bson:"user_id" validate:"required"`
+	FileName            string                `bson:"file_name" validate:"required"`
+	S3Key               string                `bson:"s3_key" json:"-" validate:"required"` // object key of the subtitle; clients download it through signed links
+	MediaFileName       string                `bson:"media_file_name,omitempty" json:"-"`  // stored name of the source media under files/
+	Duration            float64               `bson:"duration"`
+	WordsPerLine        int                   `bson:"words_per_line"`
+	Punctuation         bool                  `bson:"punctuation"`
+	ConsiderPunctuation bool                  `bson:"consider_punctuation"`
+	Format              types.SubtitleFormat  `bson:"format"`
+	LineBreak           LineBreakSettings     `bson:"line_break"`
+	Language            string                `bson:"language,omitempty"`
+	Diarization         bool                  `bson:"diarization"`
+	Speakers            map[string]string     `bson:"speakers,omitempty"` // diarization label -> display name
+	TextProcessing      TextProcessingOptions `bson:"text_processing"`
+	TranslatedFromID    *bson.ObjectID        `bson:"translated_from_id,omitempty"` // history this translation was produced from
+	ParentID            *bson.ObjectID        `bson:"parent_id,omitempty"`          // original history this version was re-rendered from
+	Version             int                   `bson:"version"`
+	CreatedAt           time.Time             `bson:"created_at"  validate:"required"`
+	UpdatedAt           time.Time             `bson:"updated_at"  validate:"required"`
+	DeletedAt           *time.Time            `bson:"deleted_at,omitempty"`
 }
 
 func (s *SRTHistory) Validate() error {
@@ -60,12 +149,63 @@ func (s *SRTHistory) SetID(id bson.ObjectID) {
 	s.ID = id
 }
 
+// SubtitleDownload is a subtitle file ready to be streamed to the client.
+type SubtitleDownload struct {
+	FileName    string
+	ContentType string
+	Body        io.ReadCloser
+}
+
 type SRTUseCase interface {
-	UploadFileAndConvertToSRT(request FileConversionRequest) (*LambdaResponse, error)
-	FindHistoriesByUserID(userID bson.ObjectID) ([]*SRTHistory, error)
+	UploadFile(request FileConversionRequest) (string, error)
+	ConvertToSRT(request FileConversionRequest) (*LambdaResponse, error)
+	FindHistories(userID bson.ObjectID, query HistoryQuery) (*Page[*SRTHistory], error)
+	CreateDownloadURL(historyID, userID bson.ObjectID, expires time.Duration) (string, error)
+	OpenDownload(historyID, userID bson.ObjectID, format types.SubtitleFormat) (*SubtitleDownload, error)
+	RenameHistory(historyID, userID bson.ObjectID, fileName string) (*SRTHistory, error)
+	DeleteHistory(historyID, userID bson.ObjectID) error
+	FindDeletedHistories(userID bson.ObjectID) ([]*SRTHistory, error)
+	RestoreHistory(historyID, userID bson.ObjectID) (*SRTHistory, error)
+	PurgeDeletedHistories() (int, error)
+	FindTranscriptByHistoryID(historyID, userID bson.ObjectID) (*Transcript, error)
+	SearchTranscripts(userID bson.ObjectID, query string, limit int) ([]*TranscriptSearchResult, error)
+	RenderHistory(request RenderRequest) (*SRTHistory, error)
+	AdjustHistoryTiming(historyID, userID bson.ObjectID, adjustment TimingAdjustment) (*SRTHistory, error)
+	TranslateHistory(historyID, userID bson.ObjectID, targetLanguage string) (*SRTHistory, error)
+	RenameSpeakers(historyID, userID bson.ObjectID, names map[string]string) (*SRTHistory, error)
+	EmbedSubtitles(ctx context.Context, historyID, userID bson.ObjectID) (string, error)
+	FindCues(historyID, userID bson.ObjectID) (*CueList, error)
+	EditCues(historyID, userID bson.ObjectID, body CueEditBody) (*CueList, error)
+	UndoCueEdit(historyID, userID bson.ObjectID, revision int) (*CueList, error)
+}
+
+// MediaSegment is one piece of a rewritten media file: either SourceLength bytes of the
+// stored source file at SourceOffset, or new Data.
+type MediaSegment struct {
+	SourceOffset int64
+	SourceLength int64
+	Data         []byte
 }
 
 type SRTRepository interface {
 	UploadFileToS3(request FileConversionRequest) (string, error)
-	TriggerLambdaFunc(request FileConversionRequest) (*LambdaResponse, error)
+	CreatePresignedUploadURL(userID bson.ObjectID, fileName string, size int64, expires time.Duration) (string, string, error)
+	CreatePresignedDownloadURL(userID bson.ObjectID, storedFileName string, expires time.Duration) (string, error)
+	GetFileFromS3(userID bson.ObjectID, storedFileName string) (io.ReadCloser, int64, error)
+	GetFileReaderFromS3(ctx context.Context, userID bson.ObjectID, storedFileName string) (io.ReaderAt, int64, error)
+	SubtitleKeyFromURL(s3URL string) (string, error)
+	UploadSubtitleToS3(userID bson.ObjectID, fileName, contentType string, content []byte) (string, error)
+	UploadVideoToS3(ctx context.Context, userID, historyID bson.ObjectID, sourceFileName, fileName string, segments []MediaSegment, expires time.Duration) (string, error)
+	CreatePresignedSubtitleURL(objectKey, fileName string, expires time.Duration) (string, error)
+	GetSubtitleFromS3(objectKey string) (io.ReadCloser, error)
+	ReplaceSubtitleInS3(objectKey, fileName, contentType string, content []byte) error
+	DeleteSubtitleFromS3(objectKey string) error
+	DeleteFileFromS3(userID bson.ObjectID, storedFileName string) error
+	DeleteVideosFromS3(userID, historyID bson.ObjectID) error
+	FindDeletedHistories(userID bson.ObjectID, since time.Time) ([]*SRTHistory, error)
+	RestoreHistory(historyID, userID bson.ObjectID, since time.Time) error
+	FindPurgeableHistories(before time.Time, limit int64) ([]*SRTHistory, error)
+	IsMediaFileShared(history *SRTHistory) (bool, error)
+	NextHistoryVersion(rootID bson.ObjectID) (int, error)
+	PurgeHistory(historyID bson.ObjectID) error
 }
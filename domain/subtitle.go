@@ -0,0 +1,52 @@
+package domain
+
+import "github.com/kwa0x2/SmartSRT-Backend/domain/types"
+
+type SubtitleUseCase interface {
+	Convert(content []byte, from, to types.SubtitleFormat) ([]byte, string, error)
+	Lint(content []byte, format types.SubtitleFormat, opts LintOptions) *LintReport
+	AdjustTiming(content []byte, format types.SubtitleFormat, adjustment TimingAdjustment) ([]byte, string, error)
+}
+
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// LintDiagnostic describes one problem found in a subtitle file. Cue is the 1-based
+// position of the cue in the file, or 0 for file-level problems.
+type LintDiagnostic struct {
+	Cue      int    `json:"cue"`
+	Line     int    `json:"line"`
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+type LintOptions struct {
+	MaxCharsPerLine int
+	MaxCPS          float64
+}
+
+type LintReport struct {
+	Format      types.SubtitleFormat `json:"format"`
+	CueCount    int                  `json:"cue_count"`
+	Valid       bool                 `json:"valid"`
+	Diagnostics []LintDiagnostic     `json:"diagnostics"`
+}
+
+// SyncPoint maps a time in the current subtitles to where it should be, in seconds.
+type SyncPoint struct {
+	From float64
+	To   float64
+}
+
+// TimingAdjustment describes one retiming operation. Offset applies to shift,
+// SyncPoints to stretch and FromFPS/ToFPS to framerate conversion.
+type TimingAdjustment struct {
+	Operation  types.TimingOperation
+	Offset     float64
+	SyncPoints [2]SyncPoint
+	FromFPS    float64
+	ToFPS      float64
+}
@@ -0,0 +1,5 @@
+package domain
+
+type Transcriber interface {
+	Transcribe(request FileConversionRequest) (*LambdaResponse, error)
+}
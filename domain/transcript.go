@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	CollectionTranscript = "transcripts"
+
+	MaxSearchResults = 50
+	MaxSearchMatches = 20 // per history
+)
+
+// TranscriptWord and TranscriptSegment timings are in seconds from the start of the media.
+type TranscriptWord struct {
+	Start float64 `bson:"start" json:"start"`
+	End   float64 `bson:"end" json:"end"`
+	Text  string  `bson:"text" json:"text"`
+}
+
+// TranscriptSegment.Speaker is the raw diarization label from the backend, e.g. "SPEAKER_00".
+type TranscriptSegment struct {
+	Start   float64          `bson:"start" json:"start"`
+	End     float64          `bson:"end" json:"end"`
+	Text    string           `bson:"text" json:"text"`
+	Speaker string           `bson:"speaker,omitempty" json:"speaker,omitempty"`
+	Words   []TranscriptWord `bson:"words,omitempty" json:"words,omitempty"`
+}
+
+// TranscriptCue is a cue edited by hand. Timings are in seconds and Speaker is the raw
+// diarization label, like on segments.
+type TranscriptCue struct {
+	Start   float64 `bson:"start" json:"start"`
+	End     float64 `bson:"end" json:"end"`
+	Text    string  `bson:"text" json:"text"`
+	Speaker string  `bson:"speaker,omitempty" json:"speaker,omitempty"`
+}
+
+// Transcript.Cues is only set once the cues of its history were edited; they then take
+// precedence over Segments whenever that history is rendered, retimed or translated.
+type Transcript struct {
+	ID        bson.ObjectID       `bson:"_id,omitempty" json:"-"`
+	HistoryID bson.ObjectID       `bson:"history_id" json:"history_id" validate:"required"`
+	UserID    bson.ObjectID       `bson:"user_id" json:"-" validate:"required"`
+	Segments  []TranscriptSegment `bson:"segments" json:"segments" validate:"required"`
+	Cues      []TranscriptCue     `bson:"cues,omitempty" json:"cues,omitempty"`
+	Revision  int                 `bson:"revision" json:"revision"`
+	Score     float64             `bson:"score,omitempty" json:"-"` // text search relevance, only set by searches
+	CreatedAt time.Time           `bson:"created_at" json:"created_at" validate:"required"`
+	UpdatedAt time.Time           `bson:"updated_at" json:"updated_at" validate:"required"`
+	DeletedAt *time.Time          `bson:"deleted_at,omitempty" json:"-"`
+}
+
+// TranscriptMatch is a cue of a search hit. Snippet is HTML-escaped with the matched
+// words wrapped in <mark>.
+type TranscriptMatch struct {
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Snippet string  `json:"snippet"`
+}
+
+type TranscriptSearchResult struct {
+	HistoryID bson.ObjectID     `json:"history_id"`
+	FileName  string            `json:"file_name"`
+	Language  string            `json:"language,omitempty"`
+	Score     float64           `json:"score"`
+	Matches   []TranscriptMatch `json:"matches"`
+}
+
+func (t *Transcript) Validate() error {
+	validate := validator.New()
+	return validate.Struct(t)
+}
+
+func (t *Transcript) GetCollectionName() string {
+	return CollectionTranscript
+}
+
+func (t *Transcript) SetID(id bson.ObjectID) {
+	t.ID = id
+}
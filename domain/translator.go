@@ -0,0 +1,6 @@
+package domain
+
+// Translator translates texts in order. An empty source language lets the provider detect it.
+type Translator interface {
+	Translate(texts []string, sourceLanguage, targetLanguage string) ([]string, error)
+}
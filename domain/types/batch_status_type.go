@@ -0,0 +1,10 @@
+package types
+
+type BatchStatus string
+
+const (
+	BatchProcessing BatchStatus = "processing"
+	BatchCompleted  BatchStatus = "completed"
+	BatchPartial    BatchStatus = "partial" // finished with at least one failed job
+	BatchFailed     BatchStatus = "failed"
+)
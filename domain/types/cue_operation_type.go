@@ -0,0 +1,10 @@
+package types
+
+type CueOperationType string
+
+const (
+	TextCueOperation   CueOperationType = "text"
+	TimingCueOperation CueOperationType = "timing"
+	SplitCueOperation  CueOperationType = "split"
+	MergeCueOperation  CueOperationType = "merge"
+)
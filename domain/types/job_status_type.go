@@ -0,0 +1,11 @@
+package types
+
+type JobStatus string
+
+const (
+	JobQueued       JobStatus = "queued"
+	JobUploading    JobStatus = "uploading"
+	JobTranscribing JobStatus = "transcribing"
+	JobCompleted    JobStatus = "completed"
+	JobFailed       JobStatus = "failed"
+)
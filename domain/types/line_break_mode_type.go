@@ -0,0 +1,8 @@
+package types
+
+type LineBreakMode string
+
+const (
+	WordsLineBreak LineBreakMode = "words"
+	CharsLineBreak LineBreakMode = "chars"
+)
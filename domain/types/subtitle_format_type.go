@@ -0,0 +1,12 @@
+package types
+
+type SubtitleFormat string
+
+const (
+	SRTFormat  SubtitleFormat = "srt"
+	VTTFormat  SubtitleFormat = "vtt"
+	ASSFormat  SubtitleFormat = "ass"
+	TTMLFormat SubtitleFormat = "ttml"
+	SBVFormat  SubtitleFormat = "sbv"
+	TXTFormat  SubtitleFormat = "txt"
+)
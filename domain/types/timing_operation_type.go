@@ -0,0 +1,9 @@
+package types
+
+type TimingOperation string
+
+const (
+	ShiftTiming     TimingOperation = "shift"
+	StretchTiming   TimingOperation = "stretch"
+	FramerateTiming TimingOperation = "framerate"
+)
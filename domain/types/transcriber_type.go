@@ -0,0 +1,9 @@
+package types
+
+type TranscriberType string
+
+const (
+	LambdaTranscriber TranscriberType = "lambda"
+	HTTPTranscriber   TranscriberType = "http"
+	StubTranscriber   TranscriberType = "stub"
+)
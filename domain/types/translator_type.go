@@ -0,0 +1,8 @@
+package types
+
+type TranslatorType string
+
+const (
+	HTTPTranslator TranslatorType = "http"
+	FakeTranslator TranslatorType = "fake"
+)
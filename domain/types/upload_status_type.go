@@ -0,0 +1,15 @@
+package types
+
+type UploadStatus string
+
+const (
+	UploadPending   UploadStatus = "pending"
+	UploadCompleted UploadStatus = "completed"
+)
+
+type UploadMethod string
+
+const (
+	UploadPresigned UploadMethod = "presigned"
+	UploadTus       UploadMethod = "tus"
+)
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"io"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	CollectionUpload = "uploads"
+)
+
+type InitiateUploadBody struct {
+	FileName string `json:"file_name"`
+	FileSize int64  `json:"file_size"`
+}
+
+type Upload struct {
+	ID             bson.ObjectID      `bson:"_id,omitempty" json:"id"`
+	UserID         bson.ObjectID      `bson:"user_id" json:"-" validate:"required"`
+	FileName       string             `bson:"file_name" json:"file_name" validate:"required"`
+	StoredFileName string             `bson:"stored_file_name,omitempty" json:"-"`
+	Method         types.UploadMethod `bson:"method" json:"method" validate:"required"`
+	Status         types.UploadStatus `bson:"status" json:"status" validate:"required"`
+	Length         int64              `bson:"length,omitempty" json:"length,omitempty"`
+	Offset         int64              `bson:"offset" json:"offset"`
+	Metadata       map[string]string  `bson:"metadata,omitempty" json:"-"`
+	MultipartID    string             `bson:"multipart_id,omitempty" json:"-"`
+	Parts          []UploadPart       `bson:"parts,omitempty" json:"-"`
+	TailKey        string             `bson:"tail_key,omitempty" json:"-"`
+	ExpiresAt      time.Time          `bson:"expires_at" json:"expires_at" validate:"required"`
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at" validate:"required"`
+	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at" validate:"required"`
+	DeletedAt      *time.Time         `bson:"deleted_at,omitempty" json:"-"`
+}
+
+// UploadPart is one S3 multipart part of a resumable upload.
+type UploadPart struct {
+	Number int32  `bson:"number"`
+	ETag   string `bson:"etag"`
+	Size   int64  `bson:"size"`
+}
+
+func (u *Upload) Validate() error {
+	validate := validator.New()
+	return validate.Struct(u)
+}
+
+func (u *Upload) GetCollectionName() string {
+	return CollectionUpload
+}
+
+func (u *Upload) SetID(id bson.ObjectID) {
+	u.ID = id
+}
+
+// UploadChunkRepository keeps resumable upload data in S3: full parts go to a multipart
+// upload of the final media object, and bytes that do not fill a part yet wait in a
+// tail object under the upload's own prefix.
+type UploadChunkRepository interface {
+	CreateMultipart(userID bson.ObjectID, fileName string) (string, string, error)
+	UploadPart(userID bson.ObjectID, storedFileName, multipartID string, number int32, content []byte) (string, error)
+	CompleteMultipart(userID bson.ObjectID, storedFileName, multipartID string, parts []UploadPart) error
+	AbortMultipart(userID bson.ObjectID, storedFileName, multipartID string) error
+	PutTail(userID, uploadID bson.ObjectID, content []byte) (string, error)
+	GetTail(key string) (io.ReadCloser, error)
+	DeleteTail(key string) error
+	DeleteTails(userID, uploadID bson.ObjectID) error
+}
+
+type UploadUseCase interface {
+	Initiate(userID bson.ObjectID, fileName string, size int64) (*Upload, string, error)
+	CreateResumable(userID bson.ObjectID, fileName string, length int64, metadata map[string]string) (*Upload, error)
+	AppendChunk(upload *Upload, offset int64, chunk io.Reader) (int64, error)
+	AssembleResumable(upload *Upload) error
+	FindOneByIDAndUserID(id, userID bson.ObjectID) (*Upload, error)
+	GetMediaDuration(upload *Upload) (float64, error)
+	MarkCompleted(id bson.ObjectID) error // utils.ErrUploadCompleted when another request completed it first
+	Reopen(id bson.ObjectID) error
+	PurgeExpiredUploads() (int, error)
+}
@@ -33,6 +33,21 @@ func Connect(r *domain.RabbitMQ, rabbitMQURI string) error {
 		return err
 	}
 
+	err = ch.ExchangeDeclare(
+		domain.ExchangeJobEvents,
+		"topic", // kind
+		true,    // durable
+		false,   // auto-delete
+		false,   // internal
+		false,   // no-wait
+		nil,     // arguments
+	)
+	if err != nil {
+		ch.Close()
+		conn.Close()
+		return err
+	}
+
 	r.Connection = conn
 	r.Channel = ch
 	r.IsConnected = true
@@ -0,0 +1,107 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func jobEventRoutingKey(fileID string) string {
+	return "jobs." + fileID
+}
+
+func PublishJobEvent(r *domain.RabbitMQ, event domain.JobEvent) error {
+	ch, err := r.Connection.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return ch.Publish(
+		domain.ExchangeJobEvents,         // exchange
+		jobEventRoutingKey(event.FileID), // routing key
+		false,                            // mandatory
+		false,                            // immediate
+		amqp.Publishing{
+			ContentType: "application/json",
+			Body:        body,
+		},
+	)
+}
+
+func SubscribeJobEvents(r *domain.RabbitMQ, ctx context.Context, fileID string) (<-chan domain.JobEvent, error) {
+	ch, err := r.Connection.Channel()
+	if err != nil {
+		return nil, err
+	}
+
+	queue, err := ch.QueueDeclare(
+		"",    // random name
+		false, // not durable
+		true,  // auto-delete
+		true,  // exclusive
+		false, // no-wait
+		nil,   // arguments
+	)
+	if err != nil {
+		ch.Close()
+		return nil, err
+	}
+
+	if err = ch.QueueBind(queue.Name, jobEventRoutingKey(fileID), domain.ExchangeJobEvents, false, nil); err != nil {
+		ch.Close()
+		return nil, err
+	}
+
+	deliveries, err := ch.Consume(
+		queue.Name,
+		"",    // consumer
+		true,  // auto-ack
+		true,  // exclusive
+		false, // no-local
+		false, // no-wait
+		nil,   // args
+	)
+	if err != nil {
+		ch.Close()
+		return nil, err
+	}
+
+	events := make(chan domain.JobEvent)
+
+	go func() {
+		defer close(events)
+		defer ch.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case delivery, ok := <-deliveries:
+				if !ok {
+					return
+				}
+
+				var event domain.JobEvent
+				if err := json.Unmarshal(delivery.Body, &event); err != nil {
+					continue
+				}
+
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
@@ -30,6 +30,33 @@ func StartWorkerPool(r *domain.RabbitMQ, numWorkers int, handler func(domain.Con
 	return nil
 }
 
+// EnqueueConversionMessage publishes msg without waiting for a reply; callers follow the
+// conversion through its job instead.
+func EnqueueConversionMessage(r *domain.RabbitMQ, msg domain.ConversionMessage) error {
+	ch, err := r.Connection.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return ch.Publish(
+		"",                      // exchange
+		domain.QueueConversions, // routing key
+		false,                   // mandatory
+		false,                   // immediate
+		amqp.Publishing{
+			ContentType:   "application/json",
+			Body:          body,
+			CorrelationId: msg.FileID,
+		},
+	)
+}
+
 func PublishConversionMessage(r *domain.RabbitMQ, ctx context.Context, msg domain.ConversionMessage) (*domain.LambdaResponse, error) {
 	ch, err := r.Connection.Channel()
 	if err != nil {
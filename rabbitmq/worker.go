@@ -63,7 +63,9 @@ func consume(w *domain.Worker) error {
 
 		response, resErr := w.Handler(convMsg)
 		if resErr != nil {
-			msg.Reject(true)
+			// The handler has already recorded the job as failed; requeueing would only
+			// rerun a conversion its client has been told is over.
+			msg.Reject(false)
 			response = &domain.LambdaResponse{
 				StatusCode: 500,
 				Body: domain.LambdaBodyResponse{
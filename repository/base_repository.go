@@ -1,12 +1,17 @@
 package repository
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 
 	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/utils"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
@@ -86,8 +91,22 @@ func (r *BaseRepository[T]) Find(ctx context.Context, filter bson.D, opts *optio
 }
 
 func (r *BaseRepository[T]) UpdateOne(ctx context.Context, filter bson.D, update bson.D, opts *options.UpdateOneOptionsBuilder) error {
-	var err error
+	_, err := r.updateOne(ctx, filter, update, opts)
+	return err
+}
+
+// UpdateOneModified is UpdateOne for conditional state transitions: it reports whether
+// a document matched filter and was changed, so only one of several racing callers wins.
+func (r *BaseRepository[T]) UpdateOneModified(ctx context.Context, filter bson.D, update bson.D) (bool, error) {
+	result, err := r.updateOne(ctx, filter, update, nil)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
 
+func (r *BaseRepository[T]) updateOne(ctx context.Context, filter bson.D, update bson.D, opts *options.UpdateOneOptionsBuilder) (*mongo.UpdateResult, error) {
 	if ctx == nil {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
@@ -104,16 +123,9 @@ func (r *BaseRepository[T]) UpdateOne(ctx context.Context, filter bson.D, update
 	filter = append(filter, bson.E{Key: "deleted_at", Value: bson.M{"$exists": false}})
 
 	if opts != nil {
-		_, err = r.collection.UpdateOne(ctx, filter, update, opts)
-	} else {
-		_, err = r.collection.UpdateOne(ctx, filter, update)
-	}
-
-	if err != nil {
-		return err
+		return r.collection.UpdateOne(ctx, filter, update, opts)
 	}
-
-	return nil
+	return r.collection.UpdateOne(ctx, filter, update)
 }
 
 func (r *BaseRepository[T]) SoftDelete(ctx context.Context, filter bson.D) error {
@@ -140,6 +152,137 @@ func (r *BaseRepository[T]) SoftDelete(ctx context.Context, filter bson.D) error
 	return nil
 }
 
+func (r *BaseRepository[T]) Count(ctx context.Context, filter bson.D) (int64, error) {
+	if ctx == nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+	}
+
+	filter = append(filter, bson.E{Key: "deleted_at", Value: bson.M{"$exists": false}})
+
+	return r.collection.CountDocuments(ctx, filter)
+}
+
+// pageCursor is the position after the last item of a page: its sort value and _id.
+// Scope fingerprints the filter and sort order, so a cursor cannot continue a
+// different query than the one that produced it.
+type pageCursor struct {
+	Field string        `bson:"f"`
+	Value bson.RawValue `bson:"v"`
+	ID    bson.ObjectID `bson:"id"`
+	Scope []byte        `bson:"s"`
+}
+
+// pageScope hashes what a cursor is only valid for: the filter, sort field and direction.
+func pageScope(filter bson.D, page domain.PageRequest) ([]byte, error) {
+	raw, err := bson.Marshal(bson.D{
+		{Key: "filter", Value: filter},
+		{Key: "sort", Value: page.SortField},
+		{Key: "descending", Value: page.Descending},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(raw)
+	return sum[:16], nil
+}
+
+func (r *BaseRepository[T]) FindPage(ctx context.Context, filter bson.D, page domain.PageRequest) (*domain.Page[T], error) {
+	if ctx == nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+	}
+
+	direction, comparison := 1, "$gt"
+	if page.Descending {
+		direction, comparison = -1, "$lt"
+	}
+
+	scope, err := pageScope(filter, page)
+	if err != nil {
+		return nil, err
+	}
+
+	if page.Cursor != "" {
+		cursor, err := decodePageCursor(page.Cursor)
+		if err != nil || cursor.Field != page.SortField || !bytes.Equal(cursor.Scope, scope) {
+			return nil, utils.ErrInvalidCursor
+		}
+		filter = append(filter, bson.E{Key: "$or", Value: bson.A{
+			bson.D{{Key: page.SortField, Value: bson.D{{Key: comparison, Value: cursor.Value}}}},
+			bson.D{
+				{Key: page.SortField, Value: cursor.Value},
+				{Key: "_id", Value: bson.D{{Key: comparison, Value: cursor.ID}}},
+			},
+		}})
+	}
+
+	// One extra item tells whether another page follows.
+	opts := options.Find().
+		SetSort(bson.D{{Key: page.SortField, Value: direction}, {Key: "_id", Value: direction}}).
+		SetLimit(page.Limit + 1)
+
+	items, err := r.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.Page[T]{Items: items}
+	if int64(len(items)) > page.Limit {
+		result.Items = items[:page.Limit]
+		if result.NextCursor, err = encodePageCursor(page.SortField, scope, result.Items[page.Limit-1]); err != nil {
+			return nil, err
+		}
+	}
+	if result.Items == nil {
+		result.Items = []T{}
+	}
+
+	return result, nil
+}
+
+func encodePageCursor(field string, scope []byte, last any) (string, error) {
+	raw, err := bson.Marshal(last)
+	if err != nil {
+		return "", err
+	}
+
+	document := bson.Raw(raw)
+	id, ok := document.Lookup("_id").ObjectIDOK()
+	if !ok {
+		return "", utils.ErrInvalidCursor
+	}
+
+	cursor, err := bson.Marshal(pageCursor{
+		Field: field,
+		Value: document.Lookup(strings.Split(field, ".")...),
+		ID:    id,
+		Scope: scope,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(cursor), nil
+}
+
+func decodePageCursor(encoded string) (*pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor pageCursor
+	if err = bson.Unmarshal(raw, &cursor); err != nil {
+		return nil, err
+	}
+
+	return &cursor, nil
+}
+
 func (r *BaseRepository[T]) GetDatabase() *mongo.Database {
 	return r.collection.Database()
 }
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+)
+
+// fakeTranslator tags each text with the target language instead of translating it,
+// so the translation pipeline can run locally and in CI.
+type fakeTranslator struct{}
+
+func NewFakeTranslator() domain.Translator {
+	return &fakeTranslator{}
+}
+
+func (ft *fakeTranslator) Translate(texts []string, sourceLanguage, targetLanguage string) ([]string, error) {
+	translated := make([]string, len(texts))
+	for i, text := range texts {
+		translated[i] = fmt.Sprintf("[%s] %s", targetLanguage, text)
+	}
+	return translated, nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+)
+
+// httpTranscriber posts the conversion request to a self-hosted endpoint (e.g. a whisper server)
+// which must reply with the same body the Lambda returns.
+type httpTranscriber struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPTranscriber(url string) domain.Transcriber {
+	return &httpTranscriber{
+		url:    url,
+		client: &http.Client{Timeout: 15 * time.Minute},
+	}
+}
+
+func (ht *httpTranscriber) Transcribe(request domain.FileConversionRequest) (*domain.LambdaResponse, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", ht.url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := ht.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var responseBody domain.LambdaBodyResponse
+	if err = json.NewDecoder(resp.Body).Decode(&responseBody); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.New(resp.Status)
+		}
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if responseBody.Message == "" {
+			return nil, errors.New(resp.Status)
+		}
+		return nil, errors.New(responseBody.Message)
+	}
+
+	return &domain.LambdaResponse{
+		StatusCode: resp.StatusCode,
+		Body:       responseBody,
+	}, nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+)
+
+type translationRequest struct {
+	SourceLanguage string   `json:"source_language,omitempty"`
+	TargetLanguage string   `json:"target_language"`
+	Texts          []string `json:"texts"`
+}
+
+type translationResponse struct {
+	Message string   `json:"message"`
+	Texts   []string `json:"texts"`
+}
+
+// httpTranslator posts texts to a self-hosted translation endpoint, which must reply
+// with the translated texts in the same order.
+type httpTranslator struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPTranslator(url string) domain.Translator {
+	return &httpTranslator{
+		url:    url,
+		client: &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+func (ht *httpTranslator) Translate(texts []string, sourceLanguage, targetLanguage string) ([]string, error) {
+	body, err := json.Marshal(translationRequest{
+		SourceLanguage: sourceLanguage,
+		TargetLanguage: targetLanguage,
+		Texts:          texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", ht.url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := ht.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var responseBody translationResponse
+	if err = json.NewDecoder(resp.Body).Decode(&responseBody); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.New(resp.Status)
+		}
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if responseBody.Message == "" {
+			return nil, errors.New(resp.Status)
+		}
+		return nil, errors.New(responseBody.Message)
+	}
+
+	if len(responseBody.Texts) != len(texts) {
+		return nil, fmt.Errorf("translator returned %d texts for %d inputs", len(responseBody.Texts), len(texts))
+	}
+
+	return responseBody.Texts, nil
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/rabbitmq"
+)
+
+type jobEventRepository struct {
+	rabbitMQ *domain.RabbitMQ
+}
+
+func NewJobEventRepository(rabbitMQ *domain.RabbitMQ) domain.JobEventRepository {
+	return &jobEventRepository{
+		rabbitMQ: rabbitMQ,
+	}
+}
+
+func (jr *jobEventRepository) Publish(event domain.JobEvent) error {
+	return rabbitmq.PublishJobEvent(jr.rabbitMQ, event)
+}
+
+func (jr *jobEventRepository) Subscribe(ctx context.Context, fileID string) (<-chan domain.JobEvent, error) {
+	return rabbitmq.SubscribeJobEvents(jr.rabbitMQ, ctx, fileID)
+}
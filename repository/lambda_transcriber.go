@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+)
+
+type lambdaTranscriber struct {
+	lambdaClient   *lambda.Client
+	lambdaFuncName string
+}
+
+func NewLambdaTranscriber(lambdaClient *lambda.Client, lambdaFuncName string) domain.Transcriber {
+	return &lambdaTranscriber{
+		lambdaClient:   lambdaClient,
+		lambdaFuncName: lambdaFuncName,
+	}
+}
+
+func (lt *lambdaTranscriber) Transcribe(request domain.FileConversionRequest) (*domain.LambdaResponse, error) {
+	jsonPayload, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &lambda.InvokeInput{
+		FunctionName: aws.String(lt.lambdaFuncName),
+		Payload:      jsonPayload,
+	}
+
+	result, err := lt.lambdaClient.Invoke(context.Background(), input)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.FunctionError != nil {
+		return nil, fmt.Errorf("lambda function error: %s", *result.FunctionError)
+	}
+
+	var rawResponse domain.LambdaResponse
+
+	if err = json.Unmarshal(result.Payload, &rawResponse); err != nil {
+		return nil, err
+	}
+
+	if rawResponse.StatusCode != http.StatusOK {
+		return nil, errors.New(rawResponse.Body.Message)
+	}
+
+	return &rawResponse, nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type lockRepository struct {
+	collection *mongo.Collection
+}
+
+func NewLockRepository(db *mongo.Database) domain.LockRepository {
+	return &lockRepository{
+		collection: db.Collection(domain.CollectionLock),
+	}
+}
+
+// Acquire takes the lease when it is free, expired or already held by owner. While
+// another owner holds it, the upsert collides on _id and Acquire reports false.
+func (lr *lockRepository) Acquire(name, owner string, ttl time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	filter := bson.D{
+		{Key: "_id", Value: name},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "owner", Value: owner}},
+			bson.D{{Key: "expires_at", Value: bson.D{{Key: "$lt", Value: now}}}},
+		}},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "owner", Value: owner},
+		{Key: "expires_at", Value: now.Add(ttl)},
+	}}}
+
+	_, err := lr.collection.UpdateOne(ctx, filter, update, options.UpdateOne().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -1,41 +1,101 @@
 package repository
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
+	"io"
+	"math"
+	"net/url"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/lambda"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/utils"
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	// minPartSize is the smallest part S3 accepts for all but the last part of a multipart upload.
+	minPartSize int64 = 5 << 20
+	// maxCopyPartSize bounds a single server-side part copy; S3 allows up to 5 GiB.
+	maxCopyPartSize int64 = 1 << 30
 )
 
 type srtRepository struct {
-	s3Client       *s3.Client
-	lambdaClient   *lambda.Client
-	lambdaFuncName string
-	bucketName     string
-	collection     *mongo.Collection
+	s3Client      *s3.Client
+	presignClient *s3.PresignClient
+	bucketName    string
+	collection    *mongo.Collection
 }
 
-func NewSRTRepository(s3Client *s3.Client, lambdaClient *lambda.Client, db *mongo.Database, bucketName, lambdaFuncName, collection string) domain.SRTRepository {
+func NewSRTRepository(s3Client *s3.Client, db *mongo.Database, bucketName, collection string) domain.SRTRepository {
 	return &srtRepository{
-		s3Client:       s3Client,
-		lambdaClient:   lambdaClient,
-		lambdaFuncName: lambdaFuncName,
-		bucketName:     bucketName,
-		collection:     db.Collection(collection),
+		s3Client:      s3Client,
+		presignClient: s3.NewPresignClient(s3Client),
+		bucketName:    bucketName,
+		collection:    db.Collection(collection),
+	}
+}
+
+func storedFileName(fileName string) string {
+	return fmt.Sprintf("%s_%d_%s", "smartsrt.com", time.Now().UTC().Unix(), fileName)
+}
+
+func fileObjectKey(userID bson.ObjectID, storedFileName string) string {
+	return fmt.Sprintf("files/%s/%s", userID.Hex(), storedFileName)
+}
+
+func subtitleObjectKey(userID bson.ObjectID, storedFileName string) string {
+	return fmt.Sprintf("srts/%s/%s", userID.Hex(), storedFileName)
+}
+
+// videoPrefix groups the videos rendered from one history, so they can be purged with it.
+func videoPrefix(userID, historyID bson.ObjectID) string {
+	return fmt.Sprintf("videos/%s/%s/", userID.Hex(), historyID.Hex())
+}
+
+// deleteObjectsWithPrefix removes every object whose key starts with prefix.
+func deleteObjectsWithPrefix(ctx context.Context, s3Client *s3.Client, bucketName, prefix string) error {
+	paginator := s3.NewListObjectsV2Paginator(s3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucketName),
+		Prefix: aws.String(prefix),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(page.Contents) == 0 {
+			continue
+		}
+
+		objects := make([]types.ObjectIdentifier, len(page.Contents))
+		for i, object := range page.Contents {
+			objects[i] = types.ObjectIdentifier{Key: object.Key}
+		}
+
+		if _, err = s3Client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(bucketName),
+			Delete: &types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		}); err != nil {
+			return err
+		}
 	}
+
+	return nil
 }
 
 func (sr *srtRepository) UploadFileToS3(request domain.FileConversionRequest) (string, error) {
-	newFileName := fmt.Sprintf("%s_%d_%s", "smartsrt.com", time.Now().UTC().Unix(), request.FileHeader.Filename)
-	objectKey := fmt.Sprintf("files/%s/%s", request.UserID.Hex(), newFileName)
+	newFileName := storedFileName(request.FileHeader.Filename)
+	objectKey := fileObjectKey(request.UserID, newFileName)
 
 	input := &s3.PutObjectInput{
 		Bucket: aws.String(sr.bucketName),
@@ -51,35 +111,486 @@ func (sr *srtRepository) UploadFileToS3(request domain.FileConversionRequest) (s
 	return newFileName, nil
 }
 
-func (sr *srtRepository) TriggerLambdaFunc(request domain.FileConversionRequest) (*domain.LambdaResponse, error) {
-	jsonPayload, err := json.Marshal(request)
+// CreatePresignedUploadURL signs a PUT for exactly size bytes; S3 rejects any other length.
+func (sr *srtRepository) CreatePresignedUploadURL(userID bson.ObjectID, fileName string, size int64, expires time.Duration) (string, string, error) {
+	newFileName := storedFileName(fileName)
+
+	input := &s3.PutObjectInput{
+		Bucket:        aws.String(sr.bucketName),
+		Key:           aws.String(fileObjectKey(userID, newFileName)),
+		ContentLength: aws.Int64(size),
+	}
+
+	request, err := sr.presignClient.PresignPutObject(context.Background(), input, s3.WithPresignExpires(expires))
+	if err != nil {
+		return "", "", err
+	}
+
+	return newFileName, request.URL, nil
+}
+
+func (sr *srtRepository) CreatePresignedDownloadURL(userID bson.ObjectID, storedFileName string, expires time.Duration) (string, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(sr.bucketName),
+		Key:    aws.String(fileObjectKey(userID, storedFileName)),
+	}
+
+	request, err := sr.presignClient.PresignGetObject(context.Background(), input, s3.WithPresignExpires(expires))
+	if err != nil {
+		return "", err
+	}
+
+	return request.URL, nil
+}
+
+func (sr *srtRepository) GetFileFromS3(userID bson.ObjectID, storedFileName string) (io.ReadCloser, int64, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(sr.bucketName),
+		Key:    aws.String(fileObjectKey(userID, storedFileName)),
+	}
+
+	output, err := sr.s3Client.GetObject(context.Background(), input)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return output.Body, aws.ToInt64(output.ContentLength), nil
+}
+
+// s3ObjectReader reads byte ranges of an S3 object, so media headers can be
+// inspected without downloading the whole file.
+type s3ObjectReader struct {
+	ctx      context.Context
+	s3Client *s3.Client
+	bucket   string
+	key      string
+	size     int64
+}
+
+func (r *s3ObjectReader) ReadAt(p []byte, off int64) (int, error) {
+	if off >= r.size {
+		return 0, io.EOF
+	}
+
+	end := min(off+int64(len(p)), r.size)
+	output, err := r.s3Client.GetObject(r.ctx, &s3.GetObjectInput{
+		Bucket: aws.String(r.bucket),
+		Key:    aws.String(r.key),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", off, end-1)),
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer output.Body.Close()
+
+	n, err := io.ReadFull(output.Body, p[:end-off])
+	if err == nil && n < len(p) {
+		err = io.EOF
+	}
+
+	return n, err
+}
+
+// GetFileReaderFromS3 returns a ranged reader over a stored media file and its size.
+// Reads stop when ctx is cancelled.
+func (sr *srtRepository) GetFileReaderFromS3(ctx context.Context, userID bson.ObjectID, storedFileName string) (io.ReaderAt, int64, error) {
+	objectKey := fileObjectKey(userID, storedFileName)
+
+	output, err := sr.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(sr.bucketName),
+		Key:    aws.String(objectKey),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	size := aws.ToInt64(output.ContentLength)
+	return &s3ObjectReader{
+		ctx:      ctx,
+		s3Client: sr.s3Client,
+		bucket:   sr.bucketName,
+		key:      objectKey,
+		size:     size,
+	}, size, nil
+}
+
+func (sr *srtRepository) UploadSubtitleToS3(userID bson.ObjectID, fileName, contentType string, content []byte) (string, error) {
+	objectKey := subtitleObjectKey(userID, storedFileName(fileName))
+
+	input := &s3.PutObjectInput{
+		Bucket:             aws.String(sr.bucketName),
+		Key:                aws.String(objectKey),
+		Body:               bytes.NewReader(content),
+		ContentType:        aws.String(contentType),
+		ContentDisposition: aws.String(fmt.Sprintf("attachment; filename=%q", fileName)),
+	}
+
+	if _, err := sr.s3Client.PutObject(context.Background(), input); err != nil {
+		return "", err
+	}
+
+	return objectKey, nil
+}
+
+// UploadVideoToS3 assembles a video from segments of the stored source file and new data
+// with a multipart upload, and returns a download link valid for expires. Source ranges
+// are copied inside S3, so only new data and short ranges next to it pass through here.
+func (sr *srtRepository) UploadVideoToS3(ctx context.Context, userID, historyID bson.ObjectID, sourceFileName, fileName string, segments []domain.MediaSegment, expires time.Duration) (string, error) {
+	objectKey := videoPrefix(userID, historyID) + storedFileName(fileName)
+
+	created, err := sr.s3Client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:             aws.String(sr.bucketName),
+		Key:                aws.String(objectKey),
+		ContentType:        aws.String("video/mp4"),
+		ContentDisposition: aws.String(fmt.Sprintf("attachment; filename=%q", fileName)),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	parts, err := sr.uploadVideoParts(ctx, objectKey, fileObjectKey(userID, sourceFileName), created.UploadId, segments)
+	if err == nil {
+		_, err = sr.s3Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(sr.bucketName),
+			Key:             aws.String(objectKey),
+			UploadId:        created.UploadId,
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+		})
+	}
+	if err != nil {
+		// ctx may be the reason for the failure, so the abort gets its own.
+		_, abortErr := sr.s3Client.AbortMultipartUpload(context.Background(), &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(sr.bucketName),
+			Key:      aws.String(objectKey),
+			UploadId: created.UploadId,
+		})
+		return "", errors.Join(err, abortErr)
+	}
+
+	request, err := sr.presignClient.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(sr.bucketName),
+		Key:    aws.String(objectKey),
+	}, s3.WithPresignExpires(expires))
+	if err != nil {
+		return "", err
+	}
+
+	return request.URL, nil
+}
+
+// uploadVideoParts writes segments as parts of a multipart upload. Source ranges of at
+// least minPartSize become server-side copies; new data and shorter ranges are buffered
+// until they fill a part, so at most one part plus the new data is held in memory.
+func (sr *srtRepository) uploadVideoParts(ctx context.Context, objectKey, sourceKey string, uploadID *string, segments []domain.MediaSegment) ([]types.CompletedPart, error) {
+	source := &s3ObjectReader{ctx: ctx, s3Client: sr.s3Client, bucket: sr.bucketName, key: sourceKey, size: math.MaxInt64}
+	copySource := (&url.URL{Path: sr.bucketName + "/" + sourceKey}).EscapedPath()
+
+	var parts []types.CompletedPart
+	var buffer bytes.Buffer
+
+	flush := func() error {
+		number := aws.Int32(int32(len(parts) + 1))
+		output, err := sr.s3Client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:     aws.String(sr.bucketName),
+			Key:        aws.String(objectKey),
+			UploadId:   uploadID,
+			PartNumber: number,
+			Body:       bytes.NewReader(buffer.Bytes()),
+		})
+		if err != nil {
+			return err
+		}
+		parts = append(parts, types.CompletedPart{ETag: output.ETag, PartNumber: number})
+		buffer.Reset()
+		return nil
+	}
+
+	copyRange := func(offset, length int64) error {
+		number := aws.Int32(int32(len(parts) + 1))
+		output, err := sr.s3Client.UploadPartCopy(ctx, &s3.UploadPartCopyInput{
+			Bucket:          aws.String(sr.bucketName),
+			Key:             aws.String(objectKey),
+			UploadId:        uploadID,
+			PartNumber:      number,
+			CopySource:      aws.String(copySource),
+			CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)),
+		})
+		if err != nil {
+			return err
+		}
+		parts = append(parts, types.CompletedPart{ETag: output.CopyPartResult.ETag, PartNumber: number})
+		return nil
+	}
+
+	readRange := func(offset, length int64) error {
+		_, err := io.Copy(&buffer, io.NewSectionReader(source, offset, length))
+		return err
+	}
+
+	for _, segment := range segments {
+		if segment.Data != nil {
+			buffer.Write(segment.Data)
+			continue
+		}
+
+		offset, remaining := segment.SourceOffset, segment.SourceLength
+		if buffer.Len() > 0 {
+			// Top buffered data up to a full part so it can go out before the copies.
+			fill := min(remaining, max(minPartSize-int64(buffer.Len()), 0))
+			if err := readRange(offset, fill); err != nil {
+				return nil, err
+			}
+			offset, remaining = offset+fill, remaining-fill
+
+			if int64(buffer.Len()) < minPartSize {
+				continue
+			}
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+
+		for remaining >= minPartSize {
+			length := min(remaining, maxCopyPartSize)
+			if err := copyRange(offset, length); err != nil {
+				return nil, err
+			}
+			offset, remaining = offset+length, remaining-length
+		}
+
+		if remaining > 0 {
+			if err := readRange(offset, remaining); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if buffer.Len() > 0 || len(parts) == 0 {
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+
+	return parts, nil
+}
+
+// SubtitleKeyFromURL returns the object key of a subtitle URL, or utils.ErrNotS3URL
+// when it does not point into our bucket.
+func (sr *srtRepository) SubtitleKeyFromURL(s3URL string) (string, error) {
+	return utils.ObjectKeyFromURL(s3URL, sr.bucketName)
+}
+
+// CreatePresignedSubtitleURL returns a link to the subtitle at objectKey valid for
+// expires, downloaded as fileName.
+func (sr *srtRepository) CreatePresignedSubtitleURL(objectKey, fileName string, expires time.Duration) (string, error) {
+	input := &s3.GetObjectInput{
+		Bucket:                     aws.String(sr.bucketName),
+		Key:                        aws.String(objectKey),
+		ResponseContentDisposition: aws.String(fmt.Sprintf("attachment; filename=%q", fileName)),
+	}
+
+	request, err := sr.presignClient.PresignGetObject(context.Background(), input, s3.WithPresignExpires(expires))
+	if err != nil {
+		return "", err
+	}
+
+	return request.URL, nil
+}
+
+// GetSubtitleFromS3 reads a stored subtitle by the key recorded on its history or job.
+func (sr *srtRepository) GetSubtitleFromS3(objectKey string) (io.ReadCloser, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(sr.bucketName),
+		Key:    aws.String(objectKey),
+	}
+
+	output, err := sr.s3Client.GetObject(context.Background(), input)
 	if err != nil {
 		return nil, err
 	}
 
-	input := &lambda.InvokeInput{
-		FunctionName: aws.String(sr.lambdaFuncName),
-		Payload:      jsonPayload,
+	return output.Body, nil
+}
+
+// ReplaceSubtitleInS3 overwrites the subtitle stored at objectKey.
+func (sr *srtRepository) ReplaceSubtitleInS3(objectKey, fileName, contentType string, content []byte) error {
+	input := &s3.PutObjectInput{
+		Bucket:             aws.String(sr.bucketName),
+		Key:                aws.String(objectKey),
+		Body:               bytes.NewReader(content),
+		ContentType:        aws.String(contentType),
+		ContentDisposition: aws.String(fmt.Sprintf("attachment; filename=%q", fileName)),
+	}
+
+	_, err := sr.s3Client.PutObject(context.Background(), input)
+	return err
+}
+
+// DeleteSubtitleFromS3 removes the subtitle stored at objectKey. Missing objects are not an error.
+func (sr *srtRepository) DeleteSubtitleFromS3(objectKey string) error {
+	_, err := sr.s3Client.DeleteObject(context.Background(), &s3.DeleteObjectInput{
+		Bucket: aws.String(sr.bucketName),
+		Key:    aws.String(objectKey),
+	})
+	return err
+}
+
+func (sr *srtRepository) DeleteFileFromS3(userID bson.ObjectID, storedFileName string) error {
+	_, err := sr.s3Client.DeleteObject(context.Background(), &s3.DeleteObjectInput{
+		Bucket: aws.String(sr.bucketName),
+		Key:    aws.String(fileObjectKey(userID, storedFileName)),
+	})
+	return err
+}
+
+// DeleteVideosFromS3 removes every video rendered from the history.
+func (sr *srtRepository) DeleteVideosFromS3(userID, historyID bson.ObjectID) error {
+	return deleteObjectsWithPrefix(context.Background(), sr.s3Client, sr.bucketName, videoPrefix(userID, historyID))
+}
+
+// FindDeletedHistories lists the user's histories deleted at or after since, most
+// recently deleted first.
+func (sr *srtRepository) FindDeletedHistories(userID bson.ObjectID, since time.Time) ([]*domain.SRTHistory, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "user_id", Value: userID},
+		{Key: "deleted_at", Value: bson.D{{Key: "$gte", Value: since}}},
 	}
 
-	result, err := sr.lambdaClient.Invoke(context.Background(), input)
+	cursor, err := sr.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "deleted_at", Value: -1}}))
 	if err != nil {
 		return nil, err
 	}
 
-	if result.FunctionError != nil {
-		return nil, fmt.Errorf("lambda function error: %s", *result.FunctionError)
+	histories := []*domain.SRTHistory{}
+	if err = cursor.All(ctx, &histories); err != nil {
+		return nil, err
+	}
+
+	return histories, nil
+}
+
+// RestoreHistory clears the deletion of a history deleted at or after since. It returns
+// mongo.ErrNoDocuments when there is no such history.
+func (sr *srtRepository) RestoreHistory(historyID, userID bson.ObjectID, since time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "_id", Value: historyID},
+		{Key: "user_id", Value: userID},
+		{Key: "deleted_at", Value: bson.D{{Key: "$gte", Value: since}}},
+	}
+	update := bson.D{
+		{Key: "$unset", Value: bson.D{{Key: "deleted_at", Value: ""}}},
+		{Key: "$set", Value: bson.D{{Key: "updated_at", Value: time.Now().UTC()}}},
+	}
+
+	result, err := sr.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
 	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// FindPurgeableHistories returns up to limit histories of any user deleted before before.
+func (sr *srtRepository) FindPurgeableHistories(before time.Time, limit int64) ([]*domain.SRTHistory, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{{Key: "deleted_at", Value: bson.D{{Key: "$lt", Value: before}}}}
 
-	var rawResponse domain.LambdaResponse
+	cursor, err := sr.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "deleted_at", Value: 1}}).SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
 
-	if err = json.Unmarshal(result.Payload, &rawResponse); err != nil {
+	var histories []*domain.SRTHistory
+	if err = cursor.All(ctx, &histories); err != nil {
 		return nil, err
 	}
 
-	if rawResponse.StatusCode != http.StatusOK {
-		return nil, errors.New(rawResponse.Body.Message)
+	return histories, nil
+}
+
+// IsMediaFileShared reports whether another history, deleted or not, was made from the
+// same source media as history. Versions and translations share it with their source.
+func (sr *srtRepository) IsMediaFileShared(history *domain.SRTHistory) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	count, err := sr.collection.CountDocuments(ctx, bson.D{
+		{Key: "user_id", Value: history.UserID},
+		{Key: "media_file_name", Value: history.MediaFileName},
+		{Key: "_id", Value: bson.D{{Key: "$ne", Value: history.ID}}},
+	}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// NextHistoryVersion reserves the next version number among the re-renders of rootID.
+// A counter document hands the numbers out atomically. It starts from the highest
+// version already stored, deleted ones included, so no number is ever used twice.
+func (sr *srtRepository) NextHistoryVersion(rootID bson.ObjectID) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var highest struct {
+		Version int `bson:"version"`
+	}
+	err := sr.collection.FindOne(ctx, bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "_id", Value: rootID}},
+		bson.D{{Key: "parent_id", Value: rootID}},
+	}}}, options.FindOne().
+		SetSort(bson.D{{Key: "version", Value: -1}}).
+		SetProjection(bson.D{{Key: "version", Value: 1}})).Decode(&highest)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, err
+	}
+
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.D{{Key: "seq", Value: bson.D{{Key: "$add", Value: bson.A{
+		bson.D{{Key: "$ifNull", Value: bson.A{"$seq", max(highest.Version, 1)}}},
+		1,
+	}}}}}}}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	counters := sr.collection.Database().Collection(domain.CollectionCounter)
+
+	var counter struct {
+		Seq int `bson:"seq"`
+	}
+	for attempt := 0; ; attempt++ {
+		err = counters.FindOneAndUpdate(ctx, bson.D{{Key: "_id", Value: "history_version:" + rootID.Hex()}}, update, opts).Decode(&counter)
+		// Two first reservations can both try to insert the counter; the loser retries
+		// and then finds it.
+		if attempt == 0 && mongo.IsDuplicateKeyError(err) {
+			continue
+		}
+		return counter.Seq, err
+	}
+}
+
+// PurgeHistory permanently removes a history with its transcript and cue edits.
+func (sr *srtRepository) PurgeHistory(historyID bson.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db := sr.collection.Database()
+	if _, err := db.Collection(domain.CollectionCueEdit).DeleteMany(ctx, bson.D{{Key: "history_id", Value: historyID}}); err != nil {
+		return err
+	}
+	if _, err := db.Collection(domain.CollectionTranscript).DeleteMany(ctx, bson.D{{Key: "history_id", Value: historyID}}); err != nil {
+		return err
 	}
 
-	return &rawResponse, nil
+	_, err := sr.collection.DeleteOne(ctx, bson.D{{Key: "_id", Value: historyID}})
+	return err
 }
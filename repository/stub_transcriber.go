@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+)
+
+// stubTranscriber returns canned output without calling any backend so the pipeline
+// can run locally and in CI.
+type stubTranscriber struct{}
+
+func NewStubTranscriber() domain.Transcriber {
+	return &stubTranscriber{}
+}
+
+var stubSegments = []domain.TranscriptSegment{
+	{Start: 0, End: 2, Text: "This is a stub transcript.", Speaker: "SPEAKER_00", Words: []domain.TranscriptWord{
+		{Start: 0, End: 0.3, Text: "This"},
+		{Start: 0.3, End: 0.5, Text: "is"},
+		{Start: 0.5, End: 0.6, Text: "a"},
+		{Start: 0.6, End: 1.1, Text: "stub"},
+		{Start: 1.1, End: 2, Text: "transcript."},
+	}},
+	{Start: 2, End: 4.5, Text: "No transcription backend was called.", Speaker: "SPEAKER_01", Words: []domain.TranscriptWord{
+		{Start: 2, End: 2.3, Text: "No"},
+		{Start: 2.3, End: 3.1, Text: "transcription"},
+		{Start: 3.1, End: 3.6, Text: "backend"},
+		{Start: 3.6, End: 3.8, Text: "was"},
+		{Start: 3.8, End: 4.5, Text: "called."},
+	}},
+}
+
+func (st *stubTranscriber) Transcribe(request domain.FileConversionRequest) (*domain.LambdaResponse, error) {
+	segments := make([]domain.TranscriptSegment, len(stubSegments))
+	copy(segments, stubSegments)
+	if !request.Diarization {
+		for i := range segments {
+			segments[i].Speaker = ""
+		}
+	}
+
+	var srt strings.Builder
+	for i, segment := range segments {
+		fmt.Fprintf(&srt, "%d\n%s --> %s\n%s\n\n", i+1, stubTimestamp(segment.Start), stubTimestamp(segment.End), segment.Text)
+	}
+
+	language := request.Language
+	if language == "" || language == "auto" {
+		language = "en"
+	}
+
+	return &domain.LambdaResponse{
+		StatusCode: http.StatusOK,
+		Body: domain.LambdaBodyResponse{
+			Message:  "stub transcription for " + request.FileName,
+			SRTURL:   "data:application/x-subrip;base64," + base64.StdEncoding.EncodeToString([]byte(srt.String())),
+			Language: language,
+			Segments: segments,
+		},
+	}, nil
+}
+
+func stubTimestamp(seconds float64) string {
+	ms := int(seconds*1000 + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/utils"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type uploadChunkRepository struct {
+	s3Client   *s3.Client
+	bucketName string
+}
+
+func NewUploadChunkRepository(s3Client *s3.Client, bucketName string) domain.UploadChunkRepository {
+	return &uploadChunkRepository{
+		s3Client:   s3Client,
+		bucketName: bucketName,
+	}
+}
+
+func uploadTailPrefix(userID, uploadID bson.ObjectID) string {
+	return fmt.Sprintf("uploads/%s/%s/", userID.Hex(), uploadID.Hex())
+}
+
+// CreateMultipart starts a multipart upload of the media object and returns its stored
+// file name and the S3 upload ID.
+func (ur *uploadChunkRepository) CreateMultipart(userID bson.ObjectID, fileName string) (string, string, error) {
+	newFileName := storedFileName(fileName)
+
+	output, err := ur.s3Client.CreateMultipartUpload(context.Background(), &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(ur.bucketName),
+		Key:    aws.String(fileObjectKey(userID, newFileName)),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return newFileName, aws.ToString(output.UploadId), nil
+}
+
+func (ur *uploadChunkRepository) UploadPart(userID bson.ObjectID, storedFileName, multipartID string, number int32, content []byte) (string, error) {
+	output, err := ur.s3Client.UploadPart(context.Background(), &s3.UploadPartInput{
+		Bucket:     aws.String(ur.bucketName),
+		Key:        aws.String(fileObjectKey(userID, storedFileName)),
+		UploadId:   aws.String(multipartID),
+		PartNumber: aws.Int32(number),
+		Body:       bytes.NewReader(content),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return aws.ToString(output.ETag), nil
+}
+
+func (ur *uploadChunkRepository) CompleteMultipart(userID bson.ObjectID, storedFileName, multipartID string, parts []domain.UploadPart) error {
+	completed := make([]types.CompletedPart, len(parts))
+	for i, part := range parts {
+		completed[i] = types.CompletedPart{
+			PartNumber: aws.Int32(part.Number),
+			ETag:       aws.String(part.ETag),
+		}
+	}
+
+	_, err := ur.s3Client.CompleteMultipartUpload(context.Background(), &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(ur.bucketName),
+		Key:             aws.String(fileObjectKey(userID, storedFileName)),
+		UploadId:        aws.String(multipartID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	})
+	return err
+}
+
+// AbortMultipart discards the uploaded parts. An upload S3 no longer knows is not an error.
+func (ur *uploadChunkRepository) AbortMultipart(userID bson.ObjectID, storedFileName, multipartID string) error {
+	_, err := ur.s3Client.AbortMultipartUpload(context.Background(), &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(ur.bucketName),
+		Key:      aws.String(fileObjectKey(userID, storedFileName)),
+		UploadId: aws.String(multipartID),
+	})
+
+	var noSuchUpload *types.NoSuchUpload
+	if errors.As(err, &noSuchUpload) {
+		return nil
+	}
+	return err
+}
+
+// PutTail stores bytes that do not fill a part yet. Every call writes a new key, so a
+// PATCH that loses a race never overwrites the tail the winner recorded.
+func (ur *uploadChunkRepository) PutTail(userID, uploadID bson.ObjectID, content []byte) (string, error) {
+	key := uploadTailPrefix(userID, uploadID) + utils.GenerateUUID()
+
+	_, err := ur.s3Client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket: aws.String(ur.bucketName),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(content),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+func (ur *uploadChunkRepository) GetTail(key string) (io.ReadCloser, error) {
+	output, err := ur.s3Client.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String(ur.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output.Body, nil
+}
+
+func (ur *uploadChunkRepository) DeleteTail(key string) error {
+	_, err := ur.s3Client.DeleteObject(context.Background(), &s3.DeleteObjectInput{
+		Bucket: aws.String(ur.bucketName),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+// DeleteTails removes every tail object of an upload, including ones left behind by
+// interrupted or losing PATCH requests.
+func (ur *uploadChunkRepository) DeleteTails(userID, uploadID bson.ObjectID) error {
+	return deleteObjectsWithPrefix(context.Background(), ur.s3Client, ur.bucketName, uploadTailPrefix(userID, uploadID))
+}
@@ -2,23 +2,27 @@ package seeder
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"time"
 
+	"github.com/kwa0x2/SmartSRT-Backend/utils"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 type Seeder struct {
-	db     *mongo.Database
-	logger *slog.Logger
+	db         *mongo.Database
+	bucketName string
+	logger     *slog.Logger
 }
 
-func NewSeeder(db *mongo.Database) *Seeder {
+func NewSeeder(db *mongo.Database, bucketName string) *Seeder {
 	return &Seeder{
-		db:     db,
-		logger: slog.Default(),
+		db:         db,
+		bucketName: bucketName,
+		logger:     slog.Default(),
 	}
 }
 
@@ -36,12 +40,16 @@ func (s *Seeder) SeedDatabase() error {
 		return err
 	}
 
+	if err := s.migrateSubtitleKeys(ctx); err != nil {
+		return err
+	}
+
 	s.logger.Info("✅ Collections and indexes created successfully")
 	return nil
 }
 
 func (s *Seeder) createCollections(ctx context.Context) error {
-	collections := []string{"users", "usage", "subscription"}
+	collections := []string{"users", "usage", "subscription", "conversion_jobs", "transcripts", "glossaries", "batches", "cue_edits"}
 
 	for _, collName := range collections {
 		err := s.db.CreateCollection(ctx, collName)
@@ -67,9 +75,12 @@ func (s *Seeder) createCollections(ctx context.Context) error {
 
 func (s *Seeder) createIndexes(ctx context.Context) error {
 	collectionIndexes := map[string][]string{
-		"users":        {"email", "phone_number", "customer_id"},
-		"usage":        {"user_id"},
-		"subscription": {"subscription_id", "user_id"},
+		"users":           {"email", "phone_number", "customer_id"},
+		"usage":           {"user_id"},
+		"subscription":    {"subscription_id", "user_id"},
+		"conversion_jobs": {"file_id"},
+		"transcripts":     {"history_id"},
+		"batches":         {"batch_id"},
 	}
 
 	for collectionName, indexFields := range collectionIndexes {
@@ -90,6 +101,128 @@ func (s *Seeder) createIndexes(ctx context.Context) error {
 		}
 	}
 
+	compoundIndexes := map[string][]bson.D{
+		"glossaries": {{{Key: "user_id", Value: 1}, {Key: "term", Value: 1}}},
+		"cue_edits":  {{{Key: "history_id", Value: 1}, {Key: "revision", Value: 1}}},
+	}
+
+	for collectionName, indexKeys := range compoundIndexes {
+		var indexes []mongo.IndexModel
+
+		for _, keys := range indexKeys {
+			indexes = append(indexes, mongo.IndexModel{
+				Keys: keys,
+				Options: options.Index().
+					SetUnique(true).
+					SetPartialFilterExpression(bson.D{{Key: "deleted_at", Value: nil}}),
+			})
+		}
+
+		if err := s.createIndexesForCollection(ctx, collectionName, indexes); err != nil {
+			return err
+		}
+	}
+
+	// History listings page by their sort field with _id breaking ties.
+	var historyIndexes []mongo.IndexModel
+	for _, field := range []string{"created_at", "file_name", "duration"} {
+		historyIndexes = append(historyIndexes, mongo.IndexModel{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: field, Value: -1}, {Key: "_id", Value: -1}},
+		})
+	}
+
+	// The trash purge scans deleted histories across all users.
+	historyIndexes = append(historyIndexes, mongo.IndexModel{
+		Keys:    bson.D{{Key: "deleted_at", Value: 1}},
+		Options: options.Index().SetSparse(true),
+	})
+
+	if err := s.createIndexesForCollection(ctx, "srt_history", historyIndexes); err != nil {
+		return err
+	}
+
+	// The upload sweeper scans pending uploads past their expiry.
+	uploadIndexes := []mongo.IndexModel{{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}},
+	}}
+
+	if err := s.createIndexesForCollection(ctx, "uploads", uploadIndexes); err != nil {
+		return err
+	}
+
+	// Transcripts mix languages, so the text index neither stems nor drops stop words.
+	textIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "user_id", Value: 1},
+			{Key: "segments.text", Value: "text"},
+			{Key: "cues.text", Value: "text"},
+		},
+		Options: options.Index().SetDefaultLanguage("none"),
+	}
+
+	return s.createIndexesForCollection(ctx, "transcripts", []mongo.IndexModel{textIndex})
+}
+
+// migrateSubtitleKeys replaces the subtitle URLs stored by earlier versions with their
+// object keys. URLs outside our bucket are left in place and reported as an error, since
+// their subtitles cannot be served until they are copied into the bucket.
+func (s *Seeder) migrateSubtitleKeys(ctx context.Context) error {
+	var foreign int
+
+	fields := map[string][2]string{
+		"srt_history":     {"s3_url", "s3_key"},
+		"conversion_jobs": {"srt_url", "subtitle_key"},
+	}
+
+	for collectionName, field := range fields {
+		collection := s.db.Collection(collectionName)
+
+		cursor, err := collection.Find(ctx, bson.D{{Key: field[0], Value: bson.D{{Key: "$exists", Value: true}}}},
+			options.Find().SetProjection(bson.D{{Key: field[0], Value: 1}}))
+		if err != nil {
+			return err
+		}
+
+		var documents []bson.Raw
+		if err = cursor.All(ctx, &documents); err != nil {
+			return err
+		}
+
+		migrated := 0
+		for _, document := range documents {
+			id, _ := document.Lookup("_id").ObjectIDOK()
+			s3URL, _ := document.Lookup(field[0]).StringValueOK()
+
+			key, err := utils.ObjectKeyFromURL(s3URL, s.bucketName)
+			if err != nil {
+				s.logger.Error("Subtitle URL is outside the S3 bucket, not migrated",
+					slog.String("collection", collectionName),
+					slog.String("id", id.Hex()))
+				foreign++
+				continue
+			}
+
+			update := bson.D{
+				{Key: "$set", Value: bson.D{{Key: field[1], Value: key}}},
+				{Key: "$unset", Value: bson.D{{Key: field[0], Value: ""}}},
+			}
+			if _, err = collection.UpdateByID(ctx, id, update); err != nil {
+				return err
+			}
+			migrated++
+		}
+
+		if migrated > 0 {
+			s.logger.Info("Subtitle URLs migrated to object keys",
+				slog.String("collection", collectionName),
+				slog.Int("count", migrated))
+		}
+	}
+
+	if foreign > 0 {
+		return fmt.Errorf("%d subtitle URLs point outside bucket %s and were not migrated", foreign, s.bucketName)
+	}
+
 	return nil
 }
 
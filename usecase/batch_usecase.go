@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type batchUseCase struct {
+	srtRepository        domain.SRTRepository
+	conversionJobUseCase domain.ConversionJobUseCase
+	batchBaseRepository  domain.BaseRepository[*domain.Batch]
+}
+
+func NewBatchUseCase(srtRepository domain.SRTRepository, conversionJobUseCase domain.ConversionJobUseCase, batchBaseRepository domain.BaseRepository[*domain.Batch]) domain.BatchUseCase {
+	return &batchUseCase{
+		srtRepository:        srtRepository,
+		conversionJobUseCase: conversionJobUseCase,
+		batchBaseRepository:  batchBaseRepository,
+	}
+}
+
+func (bu *batchUseCase) Create(batch *domain.Batch) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	batch.CreatedAt = now
+	batch.UpdatedAt = now
+
+	if err := batch.Validate(); err != nil {
+		return err
+	}
+
+	return bu.batchBaseRepository.Create(ctx, batch)
+}
+
+func (bu *batchUseCase) FindStatus(batchID string, userID bson.ObjectID) (*domain.BatchStatusResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch, err := bu.batchBaseRepository.FindOne(ctx, bson.D{
+		{Key: "batch_id", Value: batchID},
+		{Key: "user_id", Value: userID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := bu.conversionJobUseCase.FindByBatchIDAndUserID(batchID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &domain.BatchStatusResponse{Batch: batch, Jobs: jobs}
+	for _, job := range jobs {
+		switch job.Status {
+		case types.JobCompleted:
+			response.Completed++
+		case types.JobFailed:
+			response.Failed++
+		}
+	}
+	// Files whose job was never recorded count as pending until the batch is resubmitted.
+	response.Pending = batch.FileCount - response.Completed - response.Failed
+
+	switch {
+	case response.Pending > 0:
+		response.Status = types.BatchProcessing
+	case response.Failed == 0:
+		response.Status = types.BatchCompleted
+	case response.Completed == 0:
+		response.Status = types.BatchFailed
+	default:
+		response.Status = types.BatchPartial
+	}
+
+	return response, nil
+}
+
+// WriteArchive writes the subtitles of the completed jobs to w as a ZIP archive, named
+// after the uploaded files.
+func (bu *batchUseCase) WriteArchive(jobs []*domain.ConversionJob, w io.Writer) error {
+	archive := zip.NewWriter(w)
+	names := make(map[string]int)
+
+	for _, job := range jobs {
+		if job.Status != types.JobCompleted || job.SubtitleKey == "" {
+			continue
+		}
+
+		name := archiveEntryName(job)
+		names[name]++
+		if n := names[name]; n > 1 {
+			ext := filepath.Ext(name)
+			name = fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), n, ext)
+		}
+
+		if err := bu.writeArchiveEntry(archive, name, job.SubtitleKey); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+func (bu *batchUseCase) writeArchiveEntry(archive *zip.Writer, name, objectKey string) error {
+	body, err := bu.srtRepository.GetSubtitleFromS3(objectKey)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	entry, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(entry, body)
+	return err
+}
+
+// archiveEntryName swaps the media extension of the job's file for the extension of its
+// subtitle, e.g. "episode-01.mp4" becomes "episode-01.srt".
+func archiveEntryName(job *domain.ConversionJob) string {
+	ext := ".srt"
+	if path.Ext(job.SubtitleKey) != "" {
+		ext = path.Ext(job.SubtitleKey)
+	}
+
+	base := filepath.Base(job.FileName)
+	return strings.TrimSuffix(base, filepath.Ext(base)) + ext
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type conversionJobUseCase struct {
+	conversionJobBaseRepository domain.BaseRepository[*domain.ConversionJob]
+	jobEventRepository          domain.JobEventRepository
+	logger                      *slog.Logger
+}
+
+func NewConversionJobUseCase(conversionJobBaseRepository domain.BaseRepository[*domain.ConversionJob], jobEventRepository domain.JobEventRepository) domain.ConversionJobUseCase {
+	return &conversionJobUseCase{
+		conversionJobBaseRepository: conversionJobBaseRepository,
+		jobEventRepository:          jobEventRepository,
+		logger:                      slog.Default(),
+	}
+}
+
+func (cu *conversionJobUseCase) Create(job *domain.ConversionJob) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	job.Status = types.JobQueued
+	job.CreatedAt = now
+	job.UpdatedAt = now
+
+	if err := job.Validate(); err != nil {
+		return err
+	}
+
+	if err := cu.conversionJobBaseRepository.Create(ctx, job); err != nil {
+		return err
+	}
+
+	cu.publish(domain.JobEvent{FileID: job.FileID, Status: job.Status})
+	return nil
+}
+
+func (cu *conversionJobUseCase) UpdateStatus(fileID string, status types.JobStatus) error {
+	return cu.update(domain.JobEvent{FileID: fileID, Status: status}, bson.D{
+		{Key: "status", Value: status},
+	})
+}
+
+func (cu *conversionJobUseCase) Complete(fileID, historyID, subtitleKey string) error {
+	return cu.update(domain.JobEvent{FileID: fileID, Status: types.JobCompleted, HistoryID: historyID}, bson.D{
+		{Key: "status", Value: types.JobCompleted},
+		{Key: "history_id", Value: historyID},
+		{Key: "subtitle_key", Value: subtitleKey},
+	})
+}
+
+func (cu *conversionJobUseCase) Fail(fileID, reason string) error {
+	return cu.update(domain.JobEvent{FileID: fileID, Status: types.JobFailed, Error: reason}, bson.D{
+		{Key: "status", Value: types.JobFailed},
+		{Key: "error", Value: reason},
+	})
+}
+
+func (cu *conversionJobUseCase) FindOneByFileIDAndUserID(fileID string, userID bson.ObjectID) (*domain.ConversionJob, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "file_id", Value: fileID},
+		{Key: "user_id", Value: userID},
+	}
+
+	return cu.conversionJobBaseRepository.FindOne(ctx, filter)
+}
+
+func (cu *conversionJobUseCase) FindByBatchIDAndUserID(batchID string, userID bson.ObjectID) ([]*domain.ConversionJob, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "batch_id", Value: batchID},
+		{Key: "user_id", Value: userID},
+	}
+
+	return cu.conversionJobBaseRepository.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+}
+
+// DeleteByBatchID removes the jobs of a batch that could not be created in full.
+func (cu *conversionJobUseCase) DeleteByBatchID(batchID string, userID bson.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "batch_id", Value: batchID},
+		{Key: "user_id", Value: userID},
+	}
+
+	return cu.conversionJobBaseRepository.SoftDelete(ctx, filter)
+}
+
+func (cu *conversionJobUseCase) SubscribeEvents(ctx context.Context, fileID string) (<-chan domain.JobEvent, error) {
+	return cu.jobEventRepository.Subscribe(ctx, fileID)
+}
+
+// update only moves jobs that have not finished yet, so a redelivered conversion can never
+// take a completed or failed job back to an earlier status. Skipped updates publish nothing.
+func (cu *conversionJobUseCase) update(event domain.JobEvent, fields bson.D) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "file_id", Value: event.FileID},
+		{Key: "status", Value: bson.D{{Key: "$nin", Value: bson.A{types.JobCompleted, types.JobFailed}}}},
+	}
+	update := bson.D{{Key: "$set", Value: fields}}
+
+	updated, err := cu.conversionJobBaseRepository.UpdateOneModified(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		cu.logger.Warn("Conversion job update skipped, job is missing or already finished",
+			slog.String("file_id", event.FileID),
+			slog.String("status", string(event.Status)),
+		)
+		return nil
+	}
+
+	cu.publish(event)
+	return nil
+}
+
+// publish is best effort: a lost progress event must never fail the conversion itself.
+func (cu *conversionJobUseCase) publish(event domain.JobEvent) {
+	if cu.jobEventRepository == nil {
+		return
+	}
+
+	event.Timestamp = time.Now().UTC()
+	if err := cu.jobEventRepository.Publish(event); err != nil {
+		cu.logger.Warn("Conversion job event could not be published",
+			slog.String("file_id", event.FileID),
+			slog.String("status", string(event.Status)),
+			slog.String("error", err.Error()),
+		)
+	}
+}
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/utils"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type glossaryUseCase struct {
+	glossaryBaseRepository domain.BaseRepository[*domain.Glossary]
+}
+
+func NewGlossaryUseCase(glossaryBaseRepository domain.BaseRepository[*domain.Glossary]) domain.GlossaryUseCase {
+	return &glossaryUseCase{glossaryBaseRepository: glossaryBaseRepository}
+}
+
+func (gu *glossaryUseCase) Create(glossary *domain.Glossary) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	existing, err := gu.glossaryBaseRepository.Find(ctx, bson.D{{Key: "user_id", Value: glossary.UserID}}, options.Find().SetProjection(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return err
+	}
+	if len(existing) >= domain.MaxGlossaryEntries {
+		return utils.ErrGlossaryLimitReached
+	}
+
+	now := time.Now().UTC()
+	glossary.Term, glossary.Aliases = normalizeGlossaryTerms(glossary.Term, glossary.Aliases)
+	glossary.CreatedAt = now
+	glossary.UpdatedAt = now
+
+	if err = glossary.Validate(); err != nil {
+		return err
+	}
+
+	return gu.glossaryBaseRepository.Create(ctx, glossary)
+}
+
+func (gu *glossaryUseCase) FindByUserID(userID bson.ObjectID) ([]*domain.Glossary, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "term", Value: 1}})
+	return gu.glossaryBaseRepository.Find(ctx, bson.D{{Key: "user_id", Value: userID}}, opts)
+}
+
+func (gu *glossaryUseCase) Update(id, userID bson.ObjectID, body domain.GlossaryBody) (*domain.Glossary, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{{Key: "_id", Value: id}, {Key: "user_id", Value: userID}}
+	glossary, err := gu.glossaryBaseRepository.FindOne(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	glossary.Term, glossary.Aliases = normalizeGlossaryTerms(body.Term, body.Aliases)
+	glossary.UpdatedAt = time.Now().UTC()
+
+	if err = glossary.Validate(); err != nil {
+		return nil, err
+	}
+
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "term", Value: glossary.Term},
+		{Key: "aliases", Value: glossary.Aliases},
+	}}}
+	if err = gu.glossaryBaseRepository.UpdateOne(ctx, filter, update, nil); err != nil {
+		return nil, err
+	}
+
+	return glossary, nil
+}
+
+func (gu *glossaryUseCase) Delete(id, userID bson.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{{Key: "_id", Value: id}, {Key: "user_id", Value: userID}}
+	if _, err := gu.glossaryBaseRepository.FindOne(ctx, filter); err != nil {
+		return err
+	}
+
+	return gu.glossaryBaseRepository.SoftDelete(ctx, filter)
+}
+
+// normalizeGlossaryTerms trims the term and aliases and drops empty or duplicate aliases.
+func normalizeGlossaryTerms(term string, aliases []string) (string, []string) {
+	term = strings.TrimSpace(term)
+
+	seen := map[string]bool{strings.ToLower(term): true}
+	cleaned := make([]string, 0, len(aliases))
+	for _, alias := range aliases {
+		alias = strings.TrimSpace(alias)
+		if alias == "" || seen[strings.ToLower(alias)] {
+			continue
+		}
+		seen[strings.ToLower(alias)] = true
+		cleaned = append(cleaned, alias)
+	}
+
+	return term, cleaned
+}
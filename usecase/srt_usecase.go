@@ -1,36 +1,108 @@
 package usecase
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io"
 	"log/slog"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
 	"github.com/kwa0x2/SmartSRT-Backend/utils"
+	"github.com/kwa0x2/SmartSRT-Backend/utils/subtitle"
+	"github.com/kwa0x2/SmartSRT-Backend/utils/validator"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 	"go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
 )
 
+const mediaURLExpiry = time.Hour
+
 type srtUseCase struct {
-	srtRepository     domain.SRTRepository
-	usageUseCase      domain.UsageUseCase
-	srtBaseRepository domain.BaseRepository[*domain.SRTHistory]
-	logger            *slog.Logger
+	srtRepository            domain.SRTRepository
+	transcriber              domain.Transcriber
+	translator               domain.Translator
+	usageUseCase             domain.UsageUseCase
+	conversionJobUseCase     domain.ConversionJobUseCase
+	glossaryUseCase          domain.GlossaryUseCase
+	srtBaseRepository        domain.BaseRepository[*domain.SRTHistory]
+	transcriptBaseRepository domain.BaseRepository[*domain.Transcript]
+	cueEditBaseRepository    domain.BaseRepository[*domain.CueEdit]
+	logger                   *slog.Logger
 }
 
-func NewSRTUseCase(srtRepository domain.SRTRepository, usageUseCase domain.UsageUseCase, srtBaseRepository domain.BaseRepository[*domain.SRTHistory]) domain.SRTUseCase {
+func NewSRTUseCase(srtRepository domain.SRTRepository, transcriber domain.Transcriber, translator domain.Translator, usageUseCase domain.UsageUseCase, conversionJobUseCase domain.ConversionJobUseCase, glossaryUseCase domain.GlossaryUseCase, srtBaseRepository domain.BaseRepository[*domain.SRTHistory], transcriptBaseRepository domain.BaseRepository[*domain.Transcript], cueEditBaseRepository domain.BaseRepository[*domain.CueEdit]) domain.SRTUseCase {
 	return &srtUseCase{
-		srtRepository:     srtRepository,
-		usageUseCase:      usageUseCase,
-		srtBaseRepository: srtBaseRepository,
-		logger:            slog.Default(),
+		srtRepository:            srtRepository,
+		transcriber:              transcriber,
+		translator:               translator,
+		usageUseCase:             usageUseCase,
+		conversionJobUseCase:     conversionJobUseCase,
+		glossaryUseCase:          glossaryUseCase,
+		srtBaseRepository:        srtBaseRepository,
+		transcriptBaseRepository: transcriptBaseRepository,
+		cueEditBaseRepository:    cueEditBaseRepository,
+		logger:                   slog.Default(),
+	}
+}
+
+func (su *srtUseCase) UploadFile(request domain.FileConversionRequest) (string, error) {
+	if err := su.checkUsageLimit(request); err != nil {
+		return "", err
+	}
+
+	storedFileName, err := su.srtRepository.UploadFileToS3(request)
+	if err != nil {
+		su.logger.Error("SRT conversion: S3 upload failed",
+			slog.String("user_id", request.UserID.Hex()),
+			slog.String("file_name", request.FileName),
+			slog.Int64("file_size", request.FileHeader.Size),
+			slog.String("error", err.Error()),
+		)
+		return "", err
+	}
+
+	return storedFileName, nil
+}
+
+func (su *srtUseCase) ConvertToSRT(request domain.FileConversionRequest) (*domain.LambdaResponse, error) {
+	response, subtitleKey, err := su.convertToSRT(request)
+	if err != nil {
+		if jobErr := su.conversionJobUseCase.Fail(request.FileID, err.Error()); jobErr != nil {
+			su.logger.Error("SRT conversion: job failure could not be recorded",
+				slog.String("file_id", request.FileID),
+				slog.String("error", jobErr.Error()),
+			)
+		}
+		return nil, err
+	}
+
+	if jobErr := su.conversionJobUseCase.Complete(request.FileID, response.HistoryID, subtitleKey); jobErr != nil {
+		su.logger.Error("SRT conversion: job completion could not be recorded",
+			slog.String("file_id", request.FileID),
+			slog.String("error", jobErr.Error()),
+		)
+	}
+
+	return response, nil
+}
+
+func (su *srtUseCase) updateJobStatus(fileID string, status types.JobStatus) {
+	if err := su.conversionJobUseCase.UpdateStatus(fileID, status); err != nil {
+		su.logger.Error("SRT conversion: job status update failed",
+			slog.String("file_id", fileID),
+			slog.String("status", string(status)),
+			slog.String("error", err.Error()),
+		)
 	}
 }
 
-func (su *srtUseCase) UploadFileAndConvertToSRT(request domain.FileConversionRequest) (*domain.LambdaResponse, error) {
+func (su *srtUseCase) checkUsageLimit(request domain.FileConversionRequest) error {
 	canUpload, err := su.usageUseCase.CheckUsageLimit(request.UserID, request.FileDuration)
 	if err != nil {
 		su.logger.Error("SRT conversion: usage limit check failed",
@@ -39,7 +111,7 @@ func (su *srtUseCase) UploadFileAndConvertToSRT(request domain.FileConversionReq
 			slog.Float64("file_duration", request.FileDuration),
 			slog.String("error", err.Error()),
 		)
-		return nil, err
+		return err
 	}
 
 	if !canUpload {
@@ -48,31 +120,106 @@ func (su *srtUseCase) UploadFileAndConvertToSRT(request domain.FileConversionReq
 			slog.String("file_name", request.FileName),
 			slog.Float64("file_duration", request.FileDuration),
 		)
-		return nil, utils.ErrLimitReached
+		return utils.ErrLimitReached
 	}
 
-	objectKey, err := su.srtRepository.UploadFileToS3(request)
+	return nil
+}
+
+// convertToSRT expects the media to be staged already; request.FileName is the stored object name.
+func (su *srtUseCase) convertToSRT(request domain.FileConversionRequest) (*domain.LambdaResponse, string, error) {
+	if err := su.checkUsageLimit(request); err != nil {
+		return nil, "", err
+	}
+
+	su.updateJobStatus(request.FileID, types.JobTranscribing)
+
+	mediaURL, err := su.srtRepository.CreatePresignedDownloadURL(request.UserID, request.FileName, mediaURLExpiry)
 	if err != nil {
-		su.logger.Error("SRT conversion: S3 upload failed",
+		su.logger.Error("SRT conversion: media URL presign failed",
 			slog.String("user_id", request.UserID.Hex()),
 			slog.String("file_name", request.FileName),
-			slog.Int64("file_size", request.FileHeader.Size),
 			slog.String("error", err.Error()),
 		)
-		return nil, err
+		return nil, "", err
 	}
+	request.MediaURL = mediaURL
 
-	request.FileName = objectKey
+	glossaries, err := su.glossaryUseCase.FindByUserID(request.UserID)
+	if err != nil {
+		su.logger.Error("SRT conversion: glossary lookup failed",
+			slog.String("user_id", request.UserID.Hex()),
+			slog.String("error", err.Error()),
+		)
+		return nil, "", err
+	}
+	for _, glossary := range glossaries {
+		request.Vocabulary = append(request.Vocabulary, glossary.Term)
+	}
 
-	response, err := su.srtRepository.TriggerLambdaFunc(request)
+	response, err := su.transcriber.Transcribe(request)
 	if err != nil {
-		su.logger.Error("SRT conversion: Lambda trigger failed",
+		su.logger.Error("SRT conversion: transcription failed",
 			slog.String("user_id", request.UserID.Hex()),
 			slog.String("file_name", request.FileName),
-			slog.String("s3_object_key", objectKey),
 			slog.String("error", err.Error()),
 		)
-		return nil, err
+		return nil, "", err
+	}
+
+	format := request.Format
+	if format == "" {
+		format = types.SRTFormat
+	}
+	fileName := strings.TrimSuffix(request.FileHeader.Filename, filepath.Ext(request.FileHeader.Filename)) + subtitle.FileExtension(format)
+
+	language := historyLanguage(request.Language, response.Body.Language)
+	if response.Body.Language != "" && validator.NormalizeLanguage(response.Body.Language) == "" {
+		su.logger.Warn("SRT conversion: detected language is not an ISO 639-1 code, ignoring it",
+			slog.String("user_id", request.UserID.Hex()),
+			slog.String("file_name", request.FileName),
+			slog.String("detected_language", response.Body.Language),
+		)
+	}
+
+	var processed bool
+	response.Body.Segments, processed = subtitle.Chain(postProcessors(glossaries, request.TextProcessing, language)...)(response.Body.Segments)
+
+	var speakers map[string]string
+	if request.Diarization {
+		speakers = subtitle.DefaultSpeakerNames(response.Body.Segments)
+	}
+
+	// A subtitle outside our bucket could not be served or deleted later, so it is
+	// rendered again from the segments and stored with us.
+	subtitleKey, keyErr := su.srtRepository.SubtitleKeyFromURL(response.Body.SRTURL)
+	if keyErr != nil || format != types.SRTFormat || request.LineBreak.Mode == types.CharsLineBreak || len(speakers) > 0 || processed {
+		// Backends only emit word-grouped SRT, and not always to our bucket; anything else
+		// is rendered here from the segments.
+		if len(response.Body.Segments) == 0 {
+			su.logger.Error("SRT conversion: no segments to render requested format",
+				slog.String("user_id", request.UserID.Hex()),
+				slog.String("file_name", request.FileName),
+				slog.String("format", string(format)),
+			)
+			return nil, "", fmt.Errorf("transcriber returned no segments to render %s", format)
+		}
+
+		subtitleKey, err = su.uploadSubtitle(request.UserID, fileName, format, response.Body.Segments, subtitle.Options{
+			WordsPerLine:        request.WordsPerLine,
+			Punctuation:         request.Punctuation,
+			ConsiderPunctuation: request.ConsiderPunctuation,
+			LineBreak:           request.LineBreak,
+			Speakers:            speakers,
+		})
+		if err != nil {
+			su.logger.Error("SRT conversion: subtitle upload failed",
+				slog.String("user_id", request.UserID.Hex()),
+				slog.String("file_name", fileName),
+				slog.String("error", err.Error()),
+			)
+			return nil, "", err
+		}
 	}
 
 	wc := writeconcern.Majority()
@@ -88,10 +235,13 @@ func (su *srtUseCase) UploadFileAndConvertToSRT(request domain.FileConversionReq
 			slog.String("file_name", request.FileName),
 			slog.String("error", err.Error()),
 		)
-		return nil, err
+		su.discardSubtitle(request.UserID, subtitleKey)
+		return nil, "", err
 	}
 	defer session.EndSession(ctx)
 
+	var historyID bson.ObjectID
+
 	_, err = session.WithTransaction(ctx, func(txCtx context.Context) (interface{}, error) {
 		if err = su.usageUseCase.UpdateUsage(txCtx, request.UserID, request.FileDuration); err != nil {
 			su.logger.Error("SRT conversion: usage update failed",
@@ -102,15 +252,22 @@ func (su *srtUseCase) UploadFileAndConvertToSRT(request domain.FileConversionReq
 			return nil, err
 		}
 
-		fileType := filepath.Ext(request.FileHeader.Filename)
 		srtHistory := &domain.SRTHistory{
 			UserID:              request.UserID,
-			FileName:            strings.Replace(request.FileHeader.Filename, fileType, ".srt", 1),
-			S3URL:               response.Body.SRTURL,
+			FileName:            fileName,
+			S3Key:               subtitleKey,
+			MediaFileName:       request.FileName,
 			Duration:            request.FileDuration,
 			WordsPerLine:        request.WordsPerLine,
 			Punctuation:         request.Punctuation,
 			ConsiderPunctuation: request.ConsiderPunctuation,
+			Format:              format,
+			LineBreak:           request.LineBreak,
+			Language:            language,
+			Diarization:         request.Diarization,
+			Speakers:            speakers,
+			TextProcessing:      request.TextProcessing,
+			Version:             1,
 			CreatedAt:           time.Now().UTC(),
 			UpdatedAt:           time.Now().UTC(),
 		}
@@ -128,7 +285,39 @@ func (su *srtUseCase) UploadFileAndConvertToSRT(request domain.FileConversionReq
 			su.logger.Error("SRT conversion: SRT history save failed",
 				slog.String("user_id", request.UserID.Hex()),
 				slog.String("file_name", srtHistory.FileName),
-				slog.String("s3_url", response.Body.SRTURL),
+				slog.String("s3_key", subtitleKey),
+				slog.String("error", err.Error()),
+			)
+			return nil, err
+		}
+		historyID = srtHistory.ID
+
+		if len(response.Body.Segments) == 0 {
+			return nil, nil
+		}
+
+		transcript := &domain.Transcript{
+			HistoryID: srtHistory.ID,
+			UserID:    request.UserID,
+			Segments:  response.Body.Segments,
+			CreatedAt: srtHistory.CreatedAt,
+			UpdatedAt: srtHistory.UpdatedAt,
+		}
+
+		if err = transcript.Validate(); err != nil {
+			su.logger.Error("SRT conversion: transcript validation failed",
+				slog.String("user_id", request.UserID.Hex()),
+				slog.String("history_id", srtHistory.ID.Hex()),
+				slog.String("error", err.Error()),
+			)
+			return nil, err
+		}
+
+		if err = su.transcriptBaseRepository.Create(txCtx, transcript); err != nil {
+			su.logger.Error("SRT conversion: transcript save failed",
+				slog.String("user_id", request.UserID.Hex()),
+				slog.String("history_id", srtHistory.ID.Hex()),
+				slog.Int("segment_count", len(transcript.Segments)),
 				slog.String("error", err.Error()),
 			)
 			return nil, err
@@ -138,6 +327,9 @@ func (su *srtUseCase) UploadFileAndConvertToSRT(request domain.FileConversionReq
 	}, txnOptions)
 
 	if err != nil {
+		// No history points at the subtitle, so it would never be served or purged.
+		su.discardSubtitle(request.UserID, subtitleKey)
+
 		if abortErr := session.AbortTransaction(ctx); abortErr != nil {
 			su.logger.Error("SRT conversion: transaction abort failed",
 				slog.String("user_id", request.UserID.Hex()),
@@ -145,29 +337,1025 @@ func (su *srtUseCase) UploadFileAndConvertToSRT(request domain.FileConversionReq
 				slog.String("abort_error", abortErr.Error()),
 				slog.String("original_error", err.Error()),
 			)
-			return nil, abortErr
+			return nil, "", abortErr
 		}
 		su.logger.Error("SRT conversion: transaction failed",
 			slog.String("user_id", request.UserID.Hex()),
 			slog.String("file_name", request.FileName),
 			slog.String("error", err.Error()),
 		)
-		return nil, err
+		return nil, "", err
 	}
 
-	return response, nil
+	// Clients download through the history, never from the stored object directly.
+	response.Body.SRTURL = ""
+	response.HistoryID = historyID.Hex()
+
+	return response, subtitleKey, nil
 }
 
-func (su *srtUseCase) FindHistoriesByUserID(userID bson.ObjectID) ([]*domain.SRTHistory, error) {
+// FindHistories lists one page of the user's histories matching query, or all of them
+// when query is not paginated. Total counts every matching history, not just the ones
+// on the page.
+func (su *srtUseCase) FindHistories(userID bson.ObjectID, query domain.HistoryQuery) (*domain.Page[*domain.SRTHistory], error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	filter := historyFilter(userID, query)
+
+	if !query.Paginated {
+		direction := 1
+		if query.Page.Descending {
+			direction = -1
+		}
+		histories, err := su.srtBaseRepository.Find(ctx, filter, options.Find().
+			SetSort(bson.D{{Key: query.Page.SortField, Value: direction}, {Key: "_id", Value: direction}}))
+		if err != nil {
+			return nil, err
+		}
+		if histories == nil {
+			histories = []*domain.SRTHistory{}
+		}
+		return &domain.Page[*domain.SRTHistory]{Items: histories, Total: int64(len(histories))}, nil
+	}
+
+	page, err := su.srtBaseRepository.FindPage(ctx, filter, query.Page)
+	if err != nil {
+		return nil, err
+	}
+
+	if page.Total, err = su.srtBaseRepository.Count(ctx, filter); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+func historyFilter(userID bson.ObjectID, query domain.HistoryQuery) bson.D {
 	filter := bson.D{{Key: "user_id", Value: userID}}
-	result, err := su.srtBaseRepository.Find(ctx, filter, opts)
+
+	createdAt := bson.D{}
+	if query.From != nil {
+		createdAt = append(createdAt, bson.E{Key: "$gte", Value: *query.From})
+	}
+	if query.To != nil {
+		createdAt = append(createdAt, bson.E{Key: "$lt", Value: *query.To})
+	}
+	if len(createdAt) > 0 {
+		filter = append(filter, bson.E{Key: "created_at", Value: createdAt})
+	}
+
+	duration := bson.D{}
+	if query.MinDuration != nil {
+		duration = append(duration, bson.E{Key: "$gte", Value: *query.MinDuration})
+	}
+	if query.MaxDuration != nil {
+		duration = append(duration, bson.E{Key: "$lte", Value: *query.MaxDuration})
+	}
+	if len(duration) > 0 {
+		filter = append(filter, bson.E{Key: "duration", Value: duration})
+	}
+
+	if query.FileName != "" {
+		filter = append(filter, bson.E{Key: "file_name", Value: bson.Regex{Pattern: regexp.QuoteMeta(query.FileName), Options: "i"}})
+	}
+	if query.Format != "" {
+		filter = append(filter, bson.E{Key: "format", Value: query.Format})
+	}
+	if query.Language != "" {
+		filter = append(filter, bson.E{Key: "language", Value: query.Language})
+	}
+
+	return filter
+}
+
+// CreateDownloadURL returns a link to the history's subtitle file valid for expires.
+func (su *srtUseCase) CreateDownloadURL(historyID, userID bson.ObjectID, expires time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	srtHistory, err := su.srtBaseRepository.FindOne(ctx, bson.D{
+		{Key: "_id", Value: historyID},
+		{Key: "user_id", Value: userID},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return su.srtRepository.CreatePresignedSubtitleURL(srtHistory.S3Key, srtHistory.FileName, expires)
+}
+
+// OpenDownload opens the history's subtitle file for streaming. A format other than the
+// history's own is rendered on the fly from its transcript.
+func (su *srtUseCase) OpenDownload(historyID, userID bson.ObjectID, format types.SubtitleFormat) (*domain.SubtitleDownload, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	srtHistory, err := su.srtBaseRepository.FindOne(ctx, bson.D{
+		{Key: "_id", Value: historyID},
+		{Key: "user_id", Value: userID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if format == "" || format == srtHistory.Format {
+		body, err := su.srtRepository.GetSubtitleFromS3(srtHistory.S3Key)
+		if err != nil {
+			return nil, err
+		}
+		return &domain.SubtitleDownload{
+			FileName:    srtHistory.FileName,
+			ContentType: subtitle.ContentType(srtHistory.Format),
+			Body:        body,
+		}, nil
+	}
+
+	transcript, err := su.transcriptBaseRepository.FindOne(ctx, bson.D{{Key: "history_id", Value: srtHistory.ID}})
+	if err != nil {
+		return nil, err
+	}
+
+	content, contentType, err := subtitle.Render(format, historyCues(srtHistory, transcript))
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.SubtitleDownload{
+		FileName:    strings.TrimSuffix(srtHistory.FileName, filepath.Ext(srtHistory.FileName)) + subtitle.FileExtension(format),
+		ContentType: contentType,
+		Body:        io.NopCloser(bytes.NewReader(content)),
+	}, nil
+}
+
+// RenameHistory changes the display name of a history. The subtitle extension of the
+// history's format is kept, so "interview" becomes "interview.srt".
+func (su *srtUseCase) RenameHistory(historyID, userID bson.ObjectID, fileName string) (*domain.SRTHistory, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "_id", Value: historyID},
+		{Key: "user_id", Value: userID},
+	}
+
+	srtHistory, err := su.srtBaseRepository.FindOne(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := subtitle.FileExtension(srtHistory.Format)
+	if !strings.EqualFold(filepath.Ext(fileName), ext) {
+		fileName += ext
+	}
+
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "file_name", Value: fileName}}}}
+	if err = su.srtBaseRepository.UpdateOne(ctx, filter, update, nil); err != nil {
+		return nil, err
+	}
+
+	srtHistory.FileName = fileName
+	srtHistory.UpdatedAt = time.Now().UTC()
+
+	return srtHistory, nil
+}
+
+// DeleteHistory moves a history to the trash. Its files stay in S3 until
+// PurgeDeletedHistories runs after HistoryTrashRetention.
+func (su *srtUseCase) DeleteHistory(historyID, userID bson.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "_id", Value: historyID},
+		{Key: "user_id", Value: userID},
+	}
+
+	if _, err := su.srtBaseRepository.FindOne(ctx, filter); err != nil {
+		return err
+	}
+
+	return su.srtBaseRepository.SoftDelete(ctx, filter)
+}
+
+// FindDeletedHistories lists the user's histories that can still be restored.
+func (su *srtUseCase) FindDeletedHistories(userID bson.ObjectID) ([]*domain.SRTHistory, error) {
+	return su.srtRepository.FindDeletedHistories(userID, time.Now().UTC().Add(-domain.HistoryTrashRetention))
+}
+
+func (su *srtUseCase) RestoreHistory(historyID, userID bson.ObjectID) (*domain.SRTHistory, error) {
+	if err := su.srtRepository.RestoreHistory(historyID, userID, time.Now().UTC().Add(-domain.HistoryTrashRetention)); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return su.srtBaseRepository.FindOne(ctx, bson.D{
+		{Key: "_id", Value: historyID},
+		{Key: "user_id", Value: userID},
+	})
+}
+
+// PurgeDeletedHistories permanently removes the histories deleted longer than
+// HistoryTrashRetention ago, with their subtitles and any source media no other history
+// uses. Histories whose files cannot be removed are left for the next run.
+func (su *srtUseCase) PurgeDeletedHistories() (int, error) {
+	const batchSize = 100
+
+	before := time.Now().UTC().Add(-domain.HistoryTrashRetention)
+	skipped := make(map[bson.ObjectID]bool)
+	purged := 0
+
+	for {
+		histories, err := su.srtRepository.FindPurgeableHistories(before, int64(len(skipped)+batchSize))
+		if err != nil {
+			return purged, err
+		}
+
+		progressed := false
+		for _, srtHistory := range histories {
+			if skipped[srtHistory.ID] {
+				continue
+			}
+			progressed = true
+
+			if err = su.purgeHistory(srtHistory); err != nil {
+				su.logger.Error("SRT purge: history could not be removed",
+					slog.String("user_id", srtHistory.UserID.Hex()),
+					slog.String("history_id", srtHistory.ID.Hex()),
+					slog.String("error", err.Error()),
+				)
+				skipped[srtHistory.ID] = true
+				continue
+			}
+			purged++
+		}
+
+		if !progressed {
+			return purged, nil
+		}
+	}
+}
+
+func (su *srtUseCase) purgeHistory(srtHistory *domain.SRTHistory) error {
+	if err := su.srtRepository.DeleteSubtitleFromS3(srtHistory.S3Key); err != nil {
+		return err
+	}
+
+	if err := su.srtRepository.DeleteVideosFromS3(srtHistory.UserID, srtHistory.ID); err != nil {
+		return err
+	}
+
+	if srtHistory.MediaFileName != "" {
+		shared, err := su.srtRepository.IsMediaFileShared(srtHistory)
+		if err != nil {
+			return err
+		}
+		if !shared {
+			if err = su.srtRepository.DeleteFileFromS3(srtHistory.UserID, srtHistory.MediaFileName); err != nil {
+				return err
+			}
+		}
+	}
+
+	return su.srtRepository.PurgeHistory(srtHistory.ID)
+}
+
+// FindTranscriptByHistoryID returns the transcript of an active history; transcripts of
+// histories in the trash are not found, like the histories themselves.
+func (su *srtUseCase) FindTranscriptByHistoryID(historyID, userID bson.ObjectID) (*domain.Transcript, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, transcript, err := su.findHistoryWithTranscript(ctx, historyID, userID)
+	return transcript, err
+}
+
+// searchScanLimit bounds how many text index hits one search examines while filling a
+// page, since hits on deleted histories or stale segment text produce no result.
+const searchScanLimit = 500
+
+// SearchTranscripts runs a text search over the user's transcripts, best match first,
+// and returns the matching cues of each history. The text index also covers segment
+// text that later cue edits replaced, so only hits whose current cues match are kept,
+// and further hits are read until limit results are found.
+func (su *srtUseCase) SearchTranscripts(userID bson.ObjectID, query string, limit int) ([]*domain.TranscriptSearchResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	score := bson.D{{Key: "$meta", Value: "textScore"}}
+	terms := subtitle.SearchTerms(query)
+	batch := int64(max(limit*2, domain.MaxSearchResults))
+	results := make([]*domain.TranscriptSearchResult, 0, limit)
+
+	for skip := int64(0); len(results) < limit && skip < searchScanLimit; skip += batch {
+		transcripts, err := su.transcriptBaseRepository.Find(ctx, bson.D{
+			{Key: "user_id", Value: userID},
+			{Key: "$text", Value: bson.D{{Key: "$search", Value: query}}},
+		}, options.Find().
+			SetProjection(bson.D{
+				{Key: "history_id", Value: 1},
+				{Key: "segments", Value: 1},
+				{Key: "cues", Value: 1},
+				{Key: "score", Value: score},
+			}).
+			SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: 1}}).
+			SetSkip(skip).
+			SetLimit(batch))
+		if err != nil {
+			return nil, err
+		}
+
+		matched, err := su.searchResults(ctx, userID, transcripts, terms)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, matched[:min(len(matched), limit-len(results))]...)
+
+		if int64(len(transcripts)) < batch {
+			break
+		}
+	}
+
+	return results, nil
+}
+
+// searchResults builds the results of transcripts whose live history has cues matching
+// terms, in the order of transcripts.
+func (su *srtUseCase) searchResults(ctx context.Context, userID bson.ObjectID, transcripts []*domain.Transcript, terms []string) ([]*domain.TranscriptSearchResult, error) {
+	if len(transcripts) == 0 {
+		return nil, nil
+	}
+
+	historyIDs := make([]bson.ObjectID, len(transcripts))
+	for i, transcript := range transcripts {
+		historyIDs[i] = transcript.HistoryID
+	}
+
+	histories, err := su.srtBaseRepository.Find(ctx, bson.D{
+		{Key: "user_id", Value: userID},
+		{Key: "_id", Value: bson.D{{Key: "$in", Value: historyIDs}}},
+	}, options.Find())
+	if err != nil {
+		return nil, err
+	}
+
+	historiesByID := make(map[bson.ObjectID]*domain.SRTHistory, len(histories))
+	for _, srtHistory := range histories {
+		historiesByID[srtHistory.ID] = srtHistory
+	}
+
+	var results []*domain.TranscriptSearchResult
+	for _, transcript := range transcripts {
+		srtHistory, ok := historiesByID[transcript.HistoryID]
+		if !ok {
+			continue
+		}
+
+		result := &domain.TranscriptSearchResult{
+			HistoryID: srtHistory.ID,
+			FileName:  srtHistory.FileName,
+			Language:  srtHistory.Language,
+			Score:     transcript.Score,
+		}
+		for _, cue := range historyCues(srtHistory, transcript) {
+			if len(result.Matches) == domain.MaxSearchMatches {
+				break
+			}
+			if snippet, ok := subtitle.Highlight(cue.Text, terms); ok {
+				result.Matches = append(result.Matches, domain.TranscriptMatch{
+					Start:   cue.Start.Seconds(),
+					End:     cue.End.Seconds(),
+					Snippet: snippet,
+				})
+			}
+		}
+		if len(result.Matches) > 0 {
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// RenderHistory builds a new history version from the stored transcript, keeping any
+// hand-edited cues. No usage is charged since nothing is transcribed again.
+func (su *srtUseCase) RenderHistory(request domain.RenderRequest) (*domain.SRTHistory, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	source, transcript, err := su.findHistoryWithTranscript(ctx, request.HistoryID, request.UserID)
 	if err != nil {
 		return nil, err
 	}
 
-	return result, err
+	return su.createVersion(ctx, source, historySegments(transcript), request)
+}
+
+// AdjustHistoryTiming retimes the stored transcript, including edited cues, and renders
+// it as a new version with the same settings as the source.
+func (su *srtUseCase) AdjustHistoryTiming(historyID, userID bson.ObjectID, adjustment domain.TimingAdjustment) (*domain.SRTHistory, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	source, transcript, err := su.findHistoryWithTranscript(ctx, historyID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return su.createVersion(ctx, source, subtitle.AdjustSegments(historySegments(transcript), adjustment), domain.RenderRequest{
+		HistoryID:           historyID,
+		UserID:              userID,
+		WordsPerLine:        source.WordsPerLine,
+		Punctuation:         source.Punctuation,
+		ConsiderPunctuation: source.ConsiderPunctuation,
+		Format:              source.Format,
+		LineBreak:           source.LineBreak,
+	})
+}
+
+func (su *srtUseCase) findHistoryWithTranscript(ctx context.Context, historyID, userID bson.ObjectID) (*domain.SRTHistory, *domain.Transcript, error) {
+	source, err := su.srtBaseRepository.FindOne(ctx, bson.D{
+		{Key: "_id", Value: historyID},
+		{Key: "user_id", Value: userID},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	transcript, err := su.transcriptBaseRepository.FindOne(ctx, bson.D{{Key: "history_id", Value: source.ID}})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return source, transcript, nil
+}
+
+// createVersion renders segments as a new version of source.
+func (su *srtUseCase) createVersion(ctx context.Context, source *domain.SRTHistory, segments []domain.TranscriptSegment, request domain.RenderRequest) (*domain.SRTHistory, error) {
+	format := request.Format
+	if format == "" {
+		format = types.SRTFormat
+	}
+
+	rootID := source.ID
+	if source.ParentID != nil {
+		rootID = *source.ParentID
+	}
+
+	// Counting the existing versions would hand concurrent renders the same number.
+	version, err := su.srtRepository.NextHistoryVersion(rootID)
+	if err != nil {
+		return nil, err
+	}
+
+	srtHistory := &domain.SRTHistory{
+		UserID:              request.UserID,
+		FileName:            strings.TrimSuffix(source.FileName, filepath.Ext(source.FileName)) + subtitle.FileExtension(format),
+		MediaFileName:       source.MediaFileName,
+		Duration:            source.Duration,
+		WordsPerLine:        request.WordsPerLine,
+		Punctuation:         request.Punctuation,
+		ConsiderPunctuation: request.ConsiderPunctuation,
+		Format:              format,
+		LineBreak:           request.LineBreak,
+		Language:            source.Language,
+		Diarization:         source.Diarization,
+		Speakers:            source.Speakers,
+		TextProcessing:      source.TextProcessing,
+		TranslatedFromID:    source.TranslatedFromID,
+		ParentID:            &rootID,
+		Version:             version,
+	}
+
+	if err = su.saveRenderedHistory(ctx, srtHistory, segments); err != nil {
+		return nil, err
+	}
+
+	return srtHistory, nil
+}
+
+// TranslateHistory translates the stored transcript segment by segment, or cue by cue
+// once it was edited, keeping their timings, and stores the result as a new history
+// linked to the source. It fails with ErrTranslationNotConfigured without a translator.
+func (su *srtUseCase) TranslateHistory(historyID, userID bson.ObjectID, targetLanguage string) (*domain.SRTHistory, error) {
+	if su.translator == nil {
+		return nil, utils.ErrTranslationNotConfigured
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	source, transcript, err := su.findHistoryWithTranscript(ctx, historyID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceSegments := historySegments(transcript)
+	texts := make([]string, len(sourceSegments))
+	for i, segment := range sourceSegments {
+		texts[i] = segment.Text
+	}
+
+	translated, err := su.translator.Translate(texts, source.Language, targetLanguage)
+	if err != nil {
+		su.logger.Error("SRT translation: translation failed",
+			slog.String("user_id", userID.Hex()),
+			slog.String("history_id", historyID.Hex()),
+			slog.String("target_language", targetLanguage),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	// Word timings do not survive translation; BuildCues spreads each segment over its
+	// translated words so lines are re-flowed within the original segment timing.
+	segments := make([]domain.TranscriptSegment, len(sourceSegments))
+	for i, segment := range sourceSegments {
+		segments[i] = domain.TranscriptSegment{Start: segment.Start, End: segment.End, Text: translated[i], Speaker: segment.Speaker}
+	}
+
+	format := source.Format
+	if format == "" {
+		format = types.SRTFormat
+	}
+	baseName := strings.TrimSuffix(source.FileName, filepath.Ext(source.FileName))
+
+	srtHistory := &domain.SRTHistory{
+		UserID:              userID,
+		FileName:            baseName + "." + targetLanguage + subtitle.FileExtension(format),
+		MediaFileName:       source.MediaFileName,
+		Duration:            source.Duration,
+		WordsPerLine:        source.WordsPerLine,
+		Punctuation:         source.Punctuation,
+		ConsiderPunctuation: source.ConsiderPunctuation,
+		Format:              format,
+		LineBreak:           source.LineBreak,
+		Language:            targetLanguage,
+		Diarization:         source.Diarization,
+		Speakers:            source.Speakers,
+		TextProcessing:      source.TextProcessing,
+		TranslatedFromID:    &source.ID,
+		Version:             1,
+	}
+
+	if err = su.saveRenderedHistory(ctx, srtHistory, segments); err != nil {
+		return nil, err
+	}
+
+	return srtHistory, nil
+}
+
+// saveRenderedHistory renders segments with the settings on srtHistory, uploads the
+// file and stores the history together with its own copy of the segments, so every
+// entry can be rendered or edited further.
+func (su *srtUseCase) saveRenderedHistory(ctx context.Context, srtHistory *domain.SRTHistory, segments []domain.TranscriptSegment) error {
+	s3Key, err := su.uploadSubtitle(srtHistory.UserID, srtHistory.FileName, srtHistory.Format, segments, historyRenderOptions(srtHistory))
+	if err != nil {
+		su.logger.Error("SRT render: S3 upload failed",
+			slog.String("user_id", srtHistory.UserID.Hex()),
+			slog.String("file_name", srtHistory.FileName),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	now := time.Now().UTC()
+	srtHistory.S3Key = s3Key
+	srtHistory.CreatedAt = now
+	srtHistory.UpdatedAt = now
+
+	if err = srtHistory.Validate(); err != nil {
+		su.discardSubtitle(srtHistory.UserID, s3Key)
+		return err
+	}
+
+	session, err := su.srtBaseRepository.GetDatabase().Client().StartSession()
+	if err != nil {
+		su.discardSubtitle(srtHistory.UserID, s3Key)
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	txnOptions := options.Transaction().SetWriteConcern(writeconcern.Majority())
+	_, err = session.WithTransaction(ctx, func(txCtx context.Context) (interface{}, error) {
+		if err := su.srtBaseRepository.Create(txCtx, srtHistory); err != nil {
+			return nil, err
+		}
+
+		return nil, su.transcriptBaseRepository.Create(txCtx, &domain.Transcript{
+			HistoryID: srtHistory.ID,
+			UserID:    srtHistory.UserID,
+			Segments:  segments,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}, txnOptions)
+	if err != nil {
+		su.logger.Error("SRT render: transaction failed",
+			slog.String("user_id", srtHistory.UserID.Hex()),
+			slog.String("file_name", srtHistory.FileName),
+			slog.String("error", err.Error()),
+		)
+		su.discardSubtitle(srtHistory.UserID, s3Key)
+		return err
+	}
+
+	return nil
+}
+
+// postProcessors builds the chain run over transcript segments between the transcriber
+// response and rendering. Glossary replacements come first so later steps see the
+// corrected terms; masking runs last so nothing reintroduces the original word.
+func postProcessors(glossaries []*domain.Glossary, opts domain.TextProcessingOptions, language string) []subtitle.Processor {
+	var processors []subtitle.Processor
+	english := language == "" || language == "en"
+
+	if len(glossaries) > 0 {
+		processors = append(processors, subtitle.TextProcessor(subtitle.GlossaryReplacer(glossaries)))
+	}
+	if opts.RemoveFillers {
+		processors = append(processors, subtitle.RemoveFillers())
+	}
+	if opts.NormalizeNumbers && english {
+		processors = append(processors, subtitle.NormalizeNumbers())
+	}
+	if opts.SentenceCase {
+		processors = append(processors, subtitle.SentenceCase())
+	}
+	if opts.MaskProfanity && english {
+		processors = append(processors, subtitle.MaskProfanity())
+	}
+
+	return processors
+}
+
+// historyLanguage prefers the language the backend detected, reduced to its ISO 639-1
+// code, and falls back to the one requested, unless that was "auto". Detections that
+// are not a known code are dropped rather than stored.
+func historyLanguage(requested, detected string) string {
+	if detected = validator.NormalizeLanguage(detected); detected != "" {
+		return detected
+	}
+	if requested == "auto" {
+		return ""
+	}
+	return requested
+}
+
+// RenameSpeakers updates display names for diarization labels of a history and
+// republishes its subtitle file in place with the new names.
+func (su *srtUseCase) RenameSpeakers(historyID, userID bson.ObjectID, names map[string]string) (*domain.SRTHistory, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	srtHistory, transcript, err := su.findHistoryWithTranscript(ctx, historyID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !srtHistory.Diarization || len(srtHistory.Speakers) == 0 {
+		return nil, utils.ErrNoSpeakers
+	}
+
+	previous := historyCues(srtHistory, transcript)
+
+	for label, name := range names {
+		if _, ok := srtHistory.Speakers[label]; !ok {
+			return nil, fmt.Errorf("%w: %s", utils.ErrUnknownSpeaker, label)
+		}
+		srtHistory.Speakers[label] = name
+	}
+
+	if err = su.replaceSubtitle(srtHistory, historyCues(srtHistory, transcript)); err != nil {
+		su.logger.Error("SRT speakers: republish failed",
+			slog.String("user_id", userID.Hex()),
+			slog.String("history_id", historyID.Hex()),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "speakers", Value: srtHistory.Speakers}}}}
+	if err = su.srtBaseRepository.UpdateOne(ctx, bson.D{{Key: "_id", Value: historyID}}, update, nil); err != nil {
+		// The names were not stored; put the file back the way the history describes it.
+		_ = su.replaceSubtitle(srtHistory, previous)
+		return nil, err
+	}
+
+	srtHistory.UpdatedAt = time.Now().UTC()
+
+	return srtHistory, nil
+}
+
+// EmbedSubtitles muxes the history's cues into its source MP4 as a soft mov_text track
+// and uploads the result, returning a signed link to it. The media data is copied inside
+// S3; only the movie box and the subtitle samples are built here. Cancelling ctx stops
+// the S3 calls.
+func (su *srtUseCase) EmbedSubtitles(ctx context.Context, historyID, userID bson.ObjectID) (string, error) {
+	findCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	srtHistory, transcript, err := su.findHistoryWithTranscript(findCtx, historyID, userID)
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.EqualFold(filepath.Ext(srtHistory.MediaFileName), ".mp4") {
+		return "", utils.ErrMediaNotMP4
+	}
+
+	media, size, err := su.srtRepository.GetFileReaderFromS3(ctx, userID, srtHistory.MediaFileName)
+	if err != nil {
+		su.logger.Error("SRT embed: source media lookup failed",
+			slog.String("user_id", userID.Hex()),
+			slog.String("history_id", historyID.Hex()),
+			slog.String("error", err.Error()),
+		)
+		return "", err
+	}
+
+	cues := historyCues(srtHistory, transcript)
+	samples := make([]utils.TextSample, len(cues))
+	for i, cue := range cues {
+		samples[i] = utils.TextSample{Start: cue.Start, End: cue.End, Text: cue.PlainText()}
+	}
+
+	segments, err := utils.EmbedMP4Subtitles(media, size, samples, srtHistory.Language)
+	if err != nil {
+		return "", err
+	}
+
+	fileName := strings.TrimSuffix(srtHistory.FileName, filepath.Ext(srtHistory.FileName)) + ".mp4"
+	videoURL, err := su.srtRepository.UploadVideoToS3(ctx, userID, historyID, srtHistory.MediaFileName, fileName, segments, domain.DownloadURLExpiry)
+	if err != nil {
+		su.logger.Error("SRT embed: S3 upload failed",
+			slog.String("user_id", userID.Hex()),
+			slog.String("history_id", historyID.Hex()),
+			slog.String("error", err.Error()),
+		)
+		return "", err
+	}
+
+	return videoURL, nil
+}
+
+// historyRenderOptions returns the settings a stored history was rendered with.
+func historyRenderOptions(srtHistory *domain.SRTHistory) subtitle.Options {
+	opts := subtitle.Options{
+		WordsPerLine:        srtHistory.WordsPerLine,
+		Punctuation:         srtHistory.Punctuation,
+		ConsiderPunctuation: srtHistory.ConsiderPunctuation,
+		LineBreak:           srtHistory.LineBreak,
+	}
+	if srtHistory.Diarization {
+		opts.Speakers = srtHistory.Speakers
+	}
+	return opts
+}
+
+// historyCues returns the cues a history is rendered from: its hand-edited cues when
+// there are any, otherwise cues built from the transcript segments.
+func historyCues(srtHistory *domain.SRTHistory, transcript *domain.Transcript) []subtitle.Cue {
+	opts := historyRenderOptions(srtHistory)
+	if len(transcript.Cues) > 0 {
+		return subtitle.CuesFromTranscript(transcript.Cues, opts.Speakers)
+	}
+	return subtitle.BuildCues(transcript.Segments, opts)
+}
+
+// discardSubtitle deletes a subtitle object whose history could not be stored. A failed
+// delete only leaves the object behind, so it is logged rather than returned.
+func (su *srtUseCase) discardSubtitle(userID bson.ObjectID, objectKey string) {
+	if err := su.srtRepository.DeleteSubtitleFromS3(objectKey); err != nil {
+		su.logger.Warn("SRT subtitle: orphaned object could not be deleted",
+			slog.String("user_id", userID.Hex()),
+			slog.String("s3_key", objectKey),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// historySegments returns the segments new versions and translations of a history are
+// built from: one per hand-edited cue when there are any, otherwise the transcript's own.
+func historySegments(transcript *domain.Transcript) []domain.TranscriptSegment {
+	if len(transcript.Cues) > 0 {
+		return subtitle.CueSegments(transcript.Cues)
+	}
+	return transcript.Segments
+}
+
+func (su *srtUseCase) uploadSubtitle(userID bson.ObjectID, fileName string, format types.SubtitleFormat, segments []domain.TranscriptSegment, opts subtitle.Options) (string, error) {
+	content, contentType, err := subtitle.Render(format, subtitle.BuildCues(segments, opts))
+	if err != nil {
+		return "", err
+	}
+
+	return su.srtRepository.UploadSubtitleToS3(userID, fileName, contentType, content)
+}
+
+// FindCues returns the editor view of a history. Histories that were never edited get
+// cues built from the transcript with the history's render settings.
+func (su *srtUseCase) FindCues(historyID, userID bson.ObjectID) (*domain.CueList, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	srtHistory, transcript, err := su.findHistoryWithTranscript(ctx, historyID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return su.cueList(ctx, historyID, transcript.Revision, editableCues(srtHistory, transcript))
+}
+
+// EditCues applies body.Operations when body.Revision is still the transcript's current
+// revision, records the edit for undo and republishes the subtitle file in place.
+func (su *srtUseCase) EditCues(historyID, userID bson.ObjectID, body domain.CueEditBody) (*domain.CueList, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	srtHistory, transcript, err := su.findHistoryWithTranscript(ctx, historyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if transcript.Revision != body.Revision {
+		return nil, utils.ErrRevisionConflict
+	}
+
+	before := editableCues(srtHistory, transcript)
+	cues, err := subtitle.ApplyCueOperations(before, body.Operations)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", utils.ErrInvalidCueEdit, err)
+	}
+
+	revision := body.Revision + 1
+	err = su.saveCues(ctx, srtHistory, transcript.ID, body.Revision, before, cues, func(txCtx context.Context) error {
+		now := time.Now().UTC()
+		cueEdit := &domain.CueEdit{
+			HistoryID:  historyID,
+			UserID:     userID,
+			Revision:   revision,
+			Operations: body.Operations,
+			Before:     before,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		if err := cueEdit.Validate(); err != nil {
+			return err
+		}
+		return su.cueEditBaseRepository.Create(txCtx, cueEdit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return su.cueList(ctx, historyID, revision, cues)
+}
+
+// UndoCueEdit restores the cues from before the latest edit that is not undone yet.
+// The undo itself is a new revision, so repeated undos step further back.
+func (su *srtUseCase) UndoCueEdit(historyID, userID bson.ObjectID, revision int) (*domain.CueList, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	srtHistory, transcript, err := su.findHistoryWithTranscript(ctx, historyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if transcript.Revision != revision {
+		return nil, utils.ErrRevisionConflict
+	}
+
+	edits, err := su.cueEditBaseRepository.Find(ctx, bson.D{
+		{Key: "history_id", Value: historyID},
+		{Key: "undone_at", Value: bson.M{"$exists": false}},
+	}, options.Find().SetSort(bson.D{{Key: "revision", Value: -1}}).SetLimit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(edits) == 0 {
+		return nil, utils.ErrNothingToUndo
+	}
+
+	cues := edits[0].Before
+	err = su.saveCues(ctx, srtHistory, transcript.ID, revision, editableCues(srtHistory, transcript), cues, func(txCtx context.Context) error {
+		update := bson.D{{Key: "$set", Value: bson.D{{Key: "undone_at", Value: time.Now().UTC()}}}}
+		return su.cueEditBaseRepository.UpdateOne(txCtx, bson.D{{Key: "_id", Value: edits[0].ID}}, update, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return su.cueList(ctx, historyID, revision+1, cues)
+}
+
+// saveCues stores cues as the next revision of a transcript together with record, and
+// republishes the subtitle file. The revision is re-checked inside the transaction, so
+// of two concurrent edits based on the same revision only one is stored. The file is
+// written last inside the transaction: if that fails nothing is saved, and if the
+// commit fails afterwards the file is restored from previous.
+func (su *srtUseCase) saveCues(ctx context.Context, srtHistory *domain.SRTHistory, transcriptID bson.ObjectID, revision int, previous, cues []domain.TranscriptCue, record func(txCtx context.Context) error) error {
+	session, err := su.transcriptBaseRepository.GetDatabase().Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	published := false
+	txnOptions := options.Transaction().SetWriteConcern(writeconcern.Majority())
+	_, err = session.WithTransaction(ctx, func(txCtx context.Context) (interface{}, error) {
+		published = false
+
+		current, err := su.transcriptBaseRepository.FindOne(txCtx, bson.D{{Key: "_id", Value: transcriptID}})
+		if err != nil {
+			return nil, err
+		}
+		if current.Revision != revision {
+			return nil, utils.ErrRevisionConflict
+		}
+
+		update := bson.D{{Key: "$set", Value: bson.D{
+			{Key: "cues", Value: cues},
+			{Key: "revision", Value: revision + 1},
+		}}}
+		if err = su.transcriptBaseRepository.UpdateOne(txCtx, bson.D{{Key: "_id", Value: transcriptID}}, update, nil); err != nil {
+			return nil, err
+		}
+
+		if err = record(txCtx); err != nil {
+			return nil, err
+		}
+
+		if err = su.publishCues(srtHistory, cues); err != nil {
+			return nil, fmt.Errorf("%w: %v", utils.ErrSubtitlePublish, err)
+		}
+		published = true
+		return nil, nil
+	}, txnOptions)
+
+	if err != nil && published {
+		// The commit failed after the file was written; put the stored revision back.
+		_ = su.publishCues(srtHistory, previous)
+	}
+
+	return err
+}
+
+// publishCues re-renders the history from cues and overwrites its subtitle file in place.
+func (su *srtUseCase) publishCues(srtHistory *domain.SRTHistory, cues []domain.TranscriptCue) error {
+	err := su.replaceSubtitle(srtHistory, subtitle.CuesFromTranscript(cues, historyRenderOptions(srtHistory).Speakers))
+	if err != nil {
+		su.logger.Error("SRT cues: republish failed",
+			slog.String("user_id", srtHistory.UserID.Hex()),
+			slog.String("history_id", srtHistory.ID.Hex()),
+			slog.String("error", err.Error()),
+		)
+	}
+	return err
+}
+
+// replaceSubtitle renders cues in the history's format over its existing subtitle object.
+func (su *srtUseCase) replaceSubtitle(srtHistory *domain.SRTHistory, cues []subtitle.Cue) error {
+	content, contentType, err := subtitle.Render(srtHistory.Format, cues)
+	if err != nil {
+		return err
+	}
+
+	return su.srtRepository.ReplaceSubtitleInS3(srtHistory.S3Key, srtHistory.FileName, contentType, content)
+}
+
+func (su *srtUseCase) cueList(ctx context.Context, historyID bson.ObjectID, revision int, cues []domain.TranscriptCue) (*domain.CueList, error) {
+	edits, err := su.cueEditBaseRepository.Find(ctx, bson.D{{Key: "history_id", Value: historyID}}, options.Find().
+		SetSort(bson.D{{Key: "revision", Value: -1}}).
+		SetProjection(bson.D{{Key: "before", Value: 0}}))
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.CueList{
+		HistoryID: historyID,
+		Revision:  revision,
+		Cues:      cues,
+		Edits:     edits,
+	}, nil
+}
+
+// editableCues returns the stored cues of a transcript, building them from its segments
+// on the first edit. Speakers stay raw labels so renames keep applying to edited cues.
+func editableCues(srtHistory *domain.SRTHistory, transcript *domain.Transcript) []domain.TranscriptCue {
+	if len(transcript.Cues) > 0 {
+		return transcript.Cues
+	}
+
+	opts := historyRenderOptions(srtHistory)
+	if opts.Speakers != nil {
+		labels := make(map[string]string, len(opts.Speakers))
+		for label := range opts.Speakers {
+			labels[label] = label
+		}
+		opts.Speakers = labels
+	}
+
+	return subtitle.TranscriptCues(subtitle.BuildCues(transcript.Segments, opts))
 }
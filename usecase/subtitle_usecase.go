@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
+	"github.com/kwa0x2/SmartSRT-Backend/utils/subtitle"
+)
+
+type subtitleUseCase struct{}
+
+func NewSubtitleUseCase() domain.SubtitleUseCase {
+	return &subtitleUseCase{}
+}
+
+// Convert re-emits user supplied subtitles in another format. It returns the rendered
+// body and its content type.
+func (su *subtitleUseCase) Convert(content []byte, from, to types.SubtitleFormat) ([]byte, string, error) {
+	cues, err := subtitle.Parse(from, content)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return subtitle.Render(to, cues)
+}
+
+func (su *subtitleUseCase) Lint(content []byte, format types.SubtitleFormat, opts domain.LintOptions) *domain.LintReport {
+	return subtitle.Lint(format, content, opts)
+}
+
+func (su *subtitleUseCase) AdjustTiming(content []byte, format types.SubtitleFormat, adjustment domain.TimingAdjustment) ([]byte, string, error) {
+	cues, err := subtitle.Parse(format, content)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return subtitle.Render(format, subtitle.AdjustCues(cues, adjustment))
+}
@@ -0,0 +1,398 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
+	"github.com/kwa0x2/SmartSRT-Backend/utils"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	presignedUploadExpiry = 15 * time.Minute
+	uploadCompleteWindow  = time.Hour
+	resumableUploadWindow = 24 * time.Hour
+
+	// uploadPartSize is the S3 multipart part size of resumable uploads; S3 needs at
+	// least 5 MiB for every part but the last.
+	uploadPartSize int64 = 8 << 20
+
+	// expiredUploadGrace keeps expired uploads around a little longer, so a PATCH that
+	// started just before expiry can finish before its parts are discarded.
+	expiredUploadGrace = time.Hour
+	expiredUploadBatch = 100
+)
+
+type uploadUseCase struct {
+	srtRepository         domain.SRTRepository
+	uploadChunkRepository domain.UploadChunkRepository
+	uploadBaseRepository  domain.BaseRepository[*domain.Upload]
+}
+
+func NewUploadUseCase(srtRepository domain.SRTRepository, uploadChunkRepository domain.UploadChunkRepository, uploadBaseRepository domain.BaseRepository[*domain.Upload]) domain.UploadUseCase {
+	return &uploadUseCase{
+		srtRepository:         srtRepository,
+		uploadChunkRepository: uploadChunkRepository,
+		uploadBaseRepository:  uploadBaseRepository,
+	}
+}
+
+func (uu *uploadUseCase) Initiate(userID bson.ObjectID, fileName string, size int64) (*domain.Upload, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	storedFileName, uploadURL, err := uu.srtRepository.CreatePresignedUploadURL(userID, fileName, size, presignedUploadExpiry)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now().UTC()
+	upload := &domain.Upload{
+		UserID:         userID,
+		FileName:       fileName,
+		StoredFileName: storedFileName,
+		Method:         types.UploadPresigned,
+		Status:         types.UploadPending,
+		Length:         size,
+		ExpiresAt:      now.Add(uploadCompleteWindow),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if err = upload.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	if err = uu.uploadBaseRepository.Create(ctx, upload); err != nil {
+		return nil, "", err
+	}
+
+	return upload, uploadURL, nil
+}
+
+func (uu *uploadUseCase) CreateResumable(userID bson.ObjectID, fileName string, length int64, metadata map[string]string) (*domain.Upload, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	storedFileName, multipartID, err := uu.uploadChunkRepository.CreateMultipart(userID, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	upload := &domain.Upload{
+		UserID:         userID,
+		FileName:       fileName,
+		StoredFileName: storedFileName,
+		Method:         types.UploadTus,
+		Status:         types.UploadPending,
+		Length:         length,
+		Metadata:       metadata,
+		MultipartID:    multipartID,
+		ExpiresAt:      now.Add(resumableUploadWindow),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if err = upload.Validate(); err != nil {
+		return nil, errors.Join(err, uu.uploadChunkRepository.AbortMultipart(userID, storedFileName, multipartID))
+	}
+
+	if err = uu.uploadBaseRepository.Create(ctx, upload); err != nil {
+		return nil, errors.Join(err, uu.uploadChunkRepository.AbortMultipart(userID, storedFileName, multipartID))
+	}
+
+	return upload, nil
+}
+
+// AppendChunk streams a PATCH body into S3 parts of uploadPartSize, so an API instance
+// holds at most one part in memory and any instance can continue the upload. Bytes
+// received before an interrupted body are kept, as tus expects.
+func (uu *uploadUseCase) AppendChunk(upload *domain.Upload, offset int64, chunk io.Reader) (int64, error) {
+	if offset != upload.Offset {
+		return upload.Offset, utils.ErrUploadOffsetMismatch
+	}
+
+	buffer := bytes.NewBuffer(make([]byte, 0, uploadPartSize))
+	if upload.TailKey != "" {
+		tail, err := uu.uploadChunkRepository.GetTail(upload.TailKey)
+		if err != nil {
+			return upload.Offset, err
+		}
+		_, err = io.Copy(buffer, tail)
+		tail.Close()
+		if err != nil {
+			return upload.Offset, err
+		}
+	}
+
+	parts := slices.Clone(upload.Parts)
+	uploadPart := func() error {
+		number := int32(len(parts) + 1)
+		etag, err := uu.uploadChunkRepository.UploadPart(upload.UserID, upload.StoredFileName, upload.MultipartID, number, buffer.Bytes())
+		if err != nil {
+			return err
+		}
+		parts = append(parts, domain.UploadPart{Number: number, ETag: etag, Size: int64(buffer.Len())})
+		buffer.Reset()
+		return nil
+	}
+
+	var received int64
+	var chunkErr error
+	body := io.LimitReader(chunk, upload.Length-offset)
+	for {
+		n, err := io.CopyN(buffer, body, uploadPartSize-int64(buffer.Len()))
+		received += n
+
+		if int64(buffer.Len()) == uploadPartSize {
+			if chunkErr = uploadPart(); chunkErr != nil {
+				break
+			}
+		}
+
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				chunkErr = err
+			}
+			break
+		}
+	}
+
+	// Anything left in the body means the client sent more than Upload-Length.
+	if chunkErr == nil {
+		if n, _ := chunk.Read(make([]byte, 1)); n > 0 {
+			chunkErr = utils.ErrUploadTooLarge
+		}
+	}
+
+	// An empty PATCH at the end retries a last part that could not be uploaded before.
+	newOffset := offset + received
+	if received == 0 && (newOffset < upload.Length || buffer.Len() == 0) {
+		return upload.Offset, chunkErr
+	}
+
+	if newOffset == upload.Length && buffer.Len() > 0 {
+		// The last part may be smaller than uploadPartSize. If it fails, the bytes are kept as a tail.
+		if err := uploadPart(); err != nil {
+			chunkErr = err
+		}
+	}
+
+	var tailKey string
+	if buffer.Len() > 0 {
+		var err error
+		if tailKey, err = uu.uploadChunkRepository.PutTail(upload.UserID, upload.ID, buffer.Bytes()); err != nil {
+			return upload.Offset, errors.Join(chunkErr, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// A concurrent PATCH from the same offset loses here and its parts are never recorded.
+	filter := bson.D{
+		{Key: "_id", Value: upload.ID},
+		{Key: "status", Value: types.UploadPending},
+		{Key: "offset", Value: offset},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "offset", Value: newOffset},
+		{Key: "parts", Value: parts},
+		{Key: "tail_key", Value: tailKey},
+	}}}
+
+	updated, err := uu.uploadBaseRepository.UpdateOneModified(ctx, filter, update)
+	if err == nil && !updated {
+		err = utils.ErrUploadOffsetMismatch
+	}
+	if err != nil {
+		if tailKey != "" {
+			err = errors.Join(err, uu.uploadChunkRepository.DeleteTail(tailKey))
+		}
+		return upload.Offset, err
+	}
+
+	// Stale tails that fail to delete here are removed with the upload's prefix later.
+	if upload.TailKey != "" {
+		_ = uu.uploadChunkRepository.DeleteTail(upload.TailKey)
+	}
+
+	upload.Offset = newOffset
+	upload.Parts = parts
+	upload.TailKey = tailKey
+	return upload.Offset, chunkErr
+}
+
+// AssembleResumable completes the multipart upload once every byte has arrived, so the
+// media object can be probed and converted like a presigned upload. A retried final
+// PATCH finds the upload already assembled.
+func (uu *uploadUseCase) AssembleResumable(upload *domain.Upload) error {
+	if upload.MultipartID == "" {
+		return nil
+	}
+
+	if upload.Offset != upload.Length || upload.TailKey != "" {
+		return utils.ErrUploadOffsetMismatch
+	}
+
+	if err := uu.uploadChunkRepository.CompleteMultipart(upload.UserID, upload.StoredFileName, upload.MultipartID, upload.Parts); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "_id", Value: upload.ID},
+		{Key: "multipart_id", Value: upload.MultipartID},
+	}
+	update := bson.D{{Key: "$unset", Value: bson.D{
+		{Key: "multipart_id", Value: ""},
+		{Key: "parts", Value: ""},
+		{Key: "tail_key", Value: ""},
+	}}}
+
+	if err := uu.uploadBaseRepository.UpdateOne(ctx, filter, update, nil); err != nil {
+		return err
+	}
+
+	upload.MultipartID = ""
+	upload.Parts = nil
+
+	return uu.uploadChunkRepository.DeleteTails(upload.UserID, upload.ID)
+}
+
+func (uu *uploadUseCase) FindOneByIDAndUserID(id, userID bson.ObjectID) (*domain.Upload, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "_id", Value: id},
+		{Key: "user_id", Value: userID},
+	}
+
+	return uu.uploadBaseRepository.FindOne(ctx, filter)
+}
+
+// GetMediaDuration probes the uploaded file's headers with ranged reads instead of
+// downloading it. Resumable uploads must be assembled first.
+func (uu *uploadUseCase) GetMediaDuration(upload *domain.Upload) (float64, error) {
+	reader, size, err := uu.srtRepository.GetFileReaderFromS3(context.Background(), upload.UserID, upload.StoredFileName)
+	if err != nil {
+		return 0, err
+	}
+
+	if size != upload.Length {
+		return 0, utils.ErrUploadSizeMismatch
+	}
+
+	return utils.ProbeMediaDuration(reader, size, filepath.Ext(upload.FileName))
+}
+
+// MarkCompleted moves a pending upload to completed. Only one caller can win, so the
+// upload is converted and billed once even when completions race.
+func (uu *uploadUseCase) MarkCompleted(id bson.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "_id", Value: id},
+		{Key: "status", Value: types.UploadPending},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: types.UploadCompleted},
+	}}}
+
+	completed, err := uu.uploadBaseRepository.UpdateOneModified(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if !completed {
+		return utils.ErrUploadCompleted
+	}
+
+	return nil
+}
+
+// Reopen moves a completed upload back to pending when its conversion could not be
+// queued, so the client can complete it again without uploading the file twice.
+func (uu *uploadUseCase) Reopen(id bson.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "_id", Value: id},
+		{Key: "status", Value: types.UploadCompleted},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: types.UploadPending},
+	}}}
+
+	return uu.uploadBaseRepository.UpdateOne(ctx, filter, update, nil)
+}
+
+// PurgeExpiredUploads discards uploads that were never completed: their multipart parts,
+// tails and any object a presigned PUT left behind. It returns how many were purged.
+func (uu *uploadUseCase) PurgeExpiredUploads() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "status", Value: types.UploadPending},
+		{Key: "expires_at", Value: bson.D{{Key: "$lt", Value: time.Now().UTC().Add(-expiredUploadGrace)}}},
+	}
+
+	uploads, err := uu.uploadBaseRepository.Find(ctx, filter, options.Find().SetLimit(expiredUploadBatch))
+	if err != nil {
+		return 0, err
+	}
+
+	var purged int
+	var errs []error
+	for _, upload := range uploads {
+		if err = uu.purgeUpload(upload); err != nil {
+			errs = append(errs, fmt.Errorf("upload %s: %w", upload.ID.Hex(), err))
+			continue
+		}
+		purged++
+	}
+
+	return purged, errors.Join(errs...)
+}
+
+func (uu *uploadUseCase) purgeUpload(upload *domain.Upload) error {
+	if upload.MultipartID != "" {
+		if err := uu.uploadChunkRepository.AbortMultipart(upload.UserID, upload.StoredFileName, upload.MultipartID); err != nil {
+			return err
+		}
+	}
+
+	if err := uu.uploadChunkRepository.DeleteTails(upload.UserID, upload.ID); err != nil {
+		return err
+	}
+
+	if upload.StoredFileName != "" {
+		if err := uu.srtRepository.DeleteFileFromS3(upload.UserID, upload.StoredFileName); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Only a still-pending upload is removed; one completed meanwhile keeps its file.
+	return uu.uploadBaseRepository.SoftDelete(ctx, bson.D{
+		{Key: "_id", Value: upload.ID},
+		{Key: "status", Value: types.UploadPending},
+	})
+}
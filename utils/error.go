@@ -5,3 +5,19 @@ import "errors"
 var ErrSessionExpired = errors.New("session is expired")
 var ErrSessionNotFound = errors.New("session not found in dynamodb")
 var ErrLimitReached = errors.New("monthly usage limit reached")
+var ErrUploadOffsetMismatch = errors.New("upload offset mismatch")
+var ErrUploadCompleted = errors.New("upload already completed")
+var ErrUploadSizeMismatch = errors.New("uploaded file size does not match the declared size")
+var ErrUploadTooLarge = errors.New("upload exceeds declared length")
+var ErrNoSpeakers = errors.New("history has no diarization speakers")
+var ErrUnknownSpeaker = errors.New("unknown speaker label")
+var ErrGlossaryLimitReached = errors.New("glossary entry limit reached")
+var ErrUnsupportedMP4 = errors.New("unsupported or malformed MP4 file")
+var ErrRevisionConflict = errors.New("transcript was changed by another edit")
+var ErrInvalidCueEdit = errors.New("invalid cue edit")
+var ErrNothingToUndo = errors.New("no cue edit to undo")
+var ErrSubtitlePublish = errors.New("subtitle file could not be updated")
+var ErrNotS3URL = errors.New("not a URL of an object in our S3 bucket")
+var ErrInvalidCursor = errors.New("invalid page cursor")
+var ErrMediaNotMP4 = errors.New("history source media is not an MP4 file")
+var ErrTranslationNotConfigured = errors.New("translation is not configured")
@@ -1,16 +1,19 @@
 package utils
 
 import (
-	"bytes"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"math"
-
-	"github.com/alfg/mp4"
-	"github.com/go-audio/wav"
-	"github.com/hajimehoshi/go-mp3"
 )
 
+// maxMoovSize bounds the metadata read from an MP4; real moov boxes are a few MB at most.
+const maxMoovSize int64 = 64 << 20
+
+// mp3SyncSearch is how far past the ID3 tag the first MP3 frame is looked for.
+const mp3SyncSearch int64 = 64 << 10
+
 func IsValidMediaFile(fileType string) bool {
 	switch fileType {
 	case ".mp4", ".mp3", ".wav":
@@ -20,75 +23,234 @@ func IsValidMediaFile(fileType string) bool {
 	}
 }
 
-func GetMP4Duration(file io.Reader) (float64, error) {
-	buf := new(bytes.Buffer)
-	_, err := io.Copy(buf, file)
-	if err != nil {
-		return 0, err
+// ProbeMediaDuration returns the duration in whole seconds of the media file in r.
+// Only the container headers are read, so r can be a ranged reader over a remote object.
+func ProbeMediaDuration(r io.ReaderAt, size int64, fileType string) (float64, error) {
+	switch fileType {
+	case ".mp3":
+		return probeMP3Duration(r, size)
+	case ".mp4":
+		return probeMP4Duration(r, size)
+	case ".wav":
+		return probeWAVDuration(r, size)
+	default:
+		return 0, fmt.Errorf("unsupported file type: %s", fileType)
 	}
+}
 
-	mp4file, err := mp4.OpenFromBytes(buf.Bytes())
-	if err != nil {
-		return 0, err
+// readFullAt is io.ReaderAt.ReadAt that tolerates io.EOF when buf was filled.
+func readFullAt(r io.ReaderAt, buf []byte, offset int64) error {
+	n, err := r.ReadAt(buf, offset)
+	if n == len(buf) {
+		return nil
+	}
+	if err == nil || errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func probeMP4Duration(r io.ReaderAt, size int64) (float64, error) {
+	for offset := int64(0); offset < size; {
+		span, err := readMP4Span(r, offset, size)
+		if err != nil {
+			return 0, err
+		}
+
+		if span.typ == "moov" {
+			children, err := readMoov(r, span)
+			if err != nil {
+				return 0, err
+			}
+
+			mvhd := (&mp4Box{typ: "moov", children: children}).child("mvhd")
+			if mvhd == nil {
+				return 0, fmt.Errorf("MP4 mvhd box not found")
+			}
+
+			timescale, duration, err := readMvhd(mvhd.payload)
+			if err != nil {
+				return 0, err
+			}
+
+			return math.Floor(float64(duration) / float64(timescale)), nil
+		}
+
+		offset += span.size
 	}
 
-	duration := float64(mp4file.Moov.Mvhd.Duration) / float64(mp4file.Moov.Mvhd.Timescale)
-	return math.Floor(duration), nil
+	return 0, fmt.Errorf("MP4 moov box not found")
 }
 
-func GetMP3Duration(file io.Reader) (float64, error) {
-	buf := new(bytes.Buffer)
-	_, err := io.Copy(buf, file)
-	if err != nil {
+func probeWAVDuration(r io.ReaderAt, size int64) (float64, error) {
+	header := make([]byte, 16)
+	if err := readFullAt(r, header[:12], 0); err != nil {
 		return 0, err
 	}
 
-	decoder, err := mp3.NewDecoder(bytes.NewReader(buf.Bytes()))
-	if err != nil {
-		return 0, err
+	if string(header[:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return 0, fmt.Errorf("invalid WAV file")
 	}
 
-	sampleRate := decoder.SampleRate()
-	if sampleRate <= 0 {
-		return 0, fmt.Errorf("invalid sample rate")
+	var byteRate uint32
+	for offset := int64(12); offset+8 <= size; {
+		if err := readFullAt(r, header[:8], offset); err != nil {
+			return 0, err
+		}
+
+		chunkID := string(header[:4])
+		chunkSize := int64(binary.LittleEndian.Uint32(header[4:8]))
+
+		switch chunkID {
+		case "fmt ":
+			if chunkSize < 16 {
+				return 0, fmt.Errorf("invalid WAV fmt chunk")
+			}
+			if err := readFullAt(r, header, offset+8); err != nil {
+				return 0, err
+			}
+			byteRate = binary.LittleEndian.Uint32(header[8:12])
+		case "data":
+			if byteRate == 0 {
+				return 0, fmt.Errorf("invalid WAV byte rate")
+			}
+			// Streamed WAVs often carry a placeholder size; trust the file instead.
+			dataSize := min(chunkSize, size-offset-8)
+			return math.Floor(float64(dataSize) / float64(byteRate)), nil
+		}
+
+		offset += 8 + chunkSize + chunkSize%2
 	}
 
-	duration := float64(decoder.Length()) / float64(4) / float64(sampleRate)
-	return math.Floor(duration), nil
+	return 0, fmt.Errorf("WAV data chunk not found")
 }
 
-func GetWAVDuration(file io.Reader) (float64, error) {
-	buf := new(bytes.Buffer)
-	_, err := io.Copy(buf, file)
-	if err != nil {
-		return 0, err
+var mp3Bitrates = map[[2]int][]int{
+	{1, 1}: {0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+	{1, 2}: {0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+	{1, 3}: {0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	{2, 1}: {0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+	{2, 2}: {0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	{2, 3}: {0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+}
+
+var mp3SampleRates = []int{44100, 48000, 32000}
+
+type mp3Frame struct {
+	mpeg1           bool
+	mono            bool
+	bitrate         int // bits per second
+	sampleRate      int
+	samplesPerFrame int
+}
+
+func parseMP3FrameHeader(b []byte) (mp3Frame, bool) {
+	if b[0] != 0xFF || b[1]&0xE0 != 0xE0 {
+		return mp3Frame{}, false
 	}
 
-	decoder := wav.NewDecoder(bytes.NewReader(buf.Bytes()))
-	if !decoder.IsValidFile() {
-		return 0, fmt.Errorf("invalid WAV file")
+	versionBits := (b[1] >> 3) & 0x03
+	layerBits := (b[1] >> 1) & 0x03
+	bitrateIndex := int(b[2] >> 4)
+	sampleRateIndex := int((b[2] >> 2) & 0x03)
+	if versionBits == 1 || layerBits == 0 || bitrateIndex == 0 || bitrateIndex == 15 || sampleRateIndex == 3 {
+		return mp3Frame{}, false
 	}
 
-	decoder.ReadInfo()
+	layer := 4 - int(layerBits)
+	frame := mp3Frame{
+		mpeg1:      versionBits == 3,
+		mono:       b[3]>>6 == 3,
+		sampleRate: mp3SampleRates[sampleRateIndex],
+	}
 
-	audioData, err := decoder.FullPCMBuffer()
-	if err != nil {
-		return 0, err
+	table := [2]int{2, layer}
+	if frame.mpeg1 {
+		table[0] = 1
+	} else {
+		frame.sampleRate /= 2
+		if versionBits == 0 {
+			frame.sampleRate /= 2 // MPEG 2.5
+		}
+	}
+	frame.bitrate = mp3Bitrates[table][bitrateIndex] * 1000
+
+	switch {
+	case layer == 1:
+		frame.samplesPerFrame = 384
+	case layer == 3 && !frame.mpeg1:
+		frame.samplesPerFrame = 576
+	default:
+		frame.samplesPerFrame = 1152
 	}
 
-	duration := float64(audioData.NumFrames()) / float64(decoder.SampleRate)
-	return math.Floor(duration), nil
+	return frame, true
 }
 
-func GetMediaDuration(file io.Reader, fileType string) (float64, error) {
-	switch fileType {
-	case ".mp3":
-		return GetMP3Duration(file)
-	case ".mp4":
-		return GetMP4Duration(file)
-	case ".wav":
-		return GetWAVDuration(file)
-	default:
-		return 0, fmt.Errorf("unsupported file type: %s", fileType)
+// mp3VBRFrameCount reads the frame count of a Xing/Info or VBRI header in the first frame.
+func mp3VBRFrameCount(r io.ReaderAt, frameStart int64, frame mp3Frame) int64 {
+	sideInfo := int64(17)
+	switch {
+	case frame.mpeg1 && !frame.mono:
+		sideInfo = 32
+	case !frame.mpeg1 && frame.mono:
+		sideInfo = 9
+	}
+
+	xing := make([]byte, 12)
+	if readFullAt(r, xing, frameStart+4+sideInfo) == nil {
+		tag := string(xing[:4])
+		if (tag == "Xing" || tag == "Info") && binary.BigEndian.Uint32(xing[4:8])&0x01 != 0 {
+			return int64(binary.BigEndian.Uint32(xing[8:12]))
+		}
+	}
+
+	vbri := make([]byte, 18)
+	if readFullAt(r, vbri, frameStart+4+32) == nil && string(vbri[:4]) == "VBRI" {
+		return int64(binary.BigEndian.Uint32(vbri[14:18]))
 	}
+
+	return 0
+}
+
+func probeMP3Duration(r io.ReaderAt, size int64) (float64, error) {
+	start := int64(0)
+	header := make([]byte, 10)
+	if size >= 10 && readFullAt(r, header, 0) == nil && string(header[:3]) == "ID3" {
+		tagSize := int64(header[6])<<21 | int64(header[7])<<14 | int64(header[8])<<7 | int64(header[9])
+		start = 10 + tagSize
+		if header[5]&0x10 != 0 {
+			start += 10 // footer
+		}
+	}
+
+	end := size
+	if size-start >= 128 && readFullAt(r, header[:3], size-128) == nil && string(header[:3]) == "TAG" {
+		end -= 128 // ID3v1
+	}
+
+	if end-start < 4 {
+		return 0, fmt.Errorf("no MP3 frame found")
+	}
+
+	window := make([]byte, min(mp3SyncSearch, end-start))
+	if err := readFullAt(r, window, start); err != nil {
+		return 0, err
+	}
+
+	for i := 0; i+4 <= len(window); i++ {
+		frame, ok := parseMP3FrameHeader(window[i : i+4])
+		if !ok {
+			continue
+		}
+
+		frameStart := start + int64(i)
+		if frames := mp3VBRFrameCount(r, frameStart, frame); frames > 0 {
+			return math.Floor(float64(frames*int64(frame.samplesPerFrame)) / float64(frame.sampleRate)), nil
+		}
+
+		return math.Floor(float64(end-frameStart) * 8 / float64(frame.bitrate)), nil
+	}
+
+	return 0, fmt.Errorf("no MP3 frame found")
 }
@@ -0,0 +1,675 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+)
+
+// TextSample is one timed caption of an embedded subtitle track.
+type TextSample struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// movTextTimescale is the media timescale of the subtitle track, in ticks per second.
+const movTextTimescale = 1000
+
+// mp4Containers lists the boxes parsed into children when the movie box is rewritten;
+// every other box is copied through as an opaque payload.
+var mp4Containers = map[string]bool{"moov": true, "trak": true, "mdia": true, "minf": true, "stbl": true}
+
+// iso6392 maps ISO 639-1 codes to the ISO 639-2/T codes stored in the media header.
+var iso6392 = map[string]string{
+	"ar": "ara", "cs": "ces", "da": "dan", "de": "deu", "el": "ell", "en": "eng",
+	"es": "spa", "fi": "fin", "fr": "fra", "he": "heb", "hi": "hin", "hu": "hun",
+	"id": "ind", "it": "ita", "ja": "jpn", "ko": "kor", "nl": "nld", "no": "nor",
+	"pl": "pol", "pt": "por", "ro": "ron", "ru": "rus", "sv": "swe", "th": "tha",
+	"tr": "tur", "uk": "ukr", "vi": "vie", "zh": "zho",
+}
+
+type mp4Box struct {
+	typ      string
+	payload  []byte
+	children []*mp4Box
+}
+
+// mp4Span locates a top-level box in the source file.
+type mp4Span struct {
+	typ        string
+	start      int64
+	size       int64
+	headerSize int64
+	toEOF      bool
+}
+
+// EmbedMP4Subtitles plans a copy of the MP4 in src with samples added as a soft mov_text
+// (tx3g) subtitle track. Only box headers and the movie box are read: the plan refers to
+// the media data by source range, so callers can copy it without holding it in memory.
+// The movie box is moved to the end of the file and chunk offsets are rewritten to match.
+func EmbedMP4Subtitles(src io.ReaderAt, size int64, samples []TextSample, language string) ([]domain.MediaSegment, error) {
+	spans, err := scanMP4(src, size)
+	if err != nil {
+		return nil, err
+	}
+
+	moovIndex := -1
+	for i, span := range spans {
+		if span.typ == "moov" {
+			moovIndex = i
+		}
+	}
+	if moovIndex < 0 {
+		return nil, ErrUnsupportedMP4
+	}
+
+	children, err := readMoov(src, spans[moovIndex])
+	if err != nil {
+		return nil, err
+	}
+	moov := &mp4Box{typ: "moov", children: children}
+	if moov.child("mvex") != nil {
+		// Fragmented files keep their samples in moof boxes the muxer does not rewrite.
+		return nil, ErrUnsupportedMP4
+	}
+
+	mvhd := moov.child("mvhd")
+	if mvhd == nil {
+		return nil, ErrUnsupportedMP4
+	}
+	movieTimescale, movieDuration, err := readMvhd(mvhd.payload)
+	if err != nil {
+		return nil, err
+	}
+
+	width, height := videoDimensions(moov)
+
+	movieLength := time.Duration(float64(movieDuration) / float64(movieTimescale) * float64(time.Second))
+	sampleData, durations, sizes := buildTextSamples(samples, movieLength)
+	if sampleData == nil {
+		return nil, fmt.Errorf("no subtitle cues within the video duration")
+	}
+
+	var segments []domain.MediaSegment
+	var written int64
+	newStarts := make(map[int]int64, len(spans))
+	for i, span := range spans {
+		if i == moovIndex {
+			continue
+		}
+		newStarts[i] = written
+		written += span.size
+
+		start := span.start
+		if span.toEOF {
+			// A box running to the end of the file needs an explicit size once data follows it.
+			if span.size > math.MaxUint32 {
+				return nil, ErrUnsupportedMP4
+			}
+			header := binary.BigEndian.AppendUint32(nil, uint32(span.size))
+			segments = append(segments, domain.MediaSegment{Data: append(header, span.typ...)})
+			start += span.headerSize
+		}
+
+		if n := len(segments); n > 0 && segments[n-1].Data == nil && segments[n-1].SourceOffset+segments[n-1].SourceLength == start {
+			segments[n-1].SourceLength += span.start + span.size - start
+		} else {
+			segments = append(segments, domain.MediaSegment{SourceOffset: start, SourceLength: span.start + span.size - start})
+		}
+	}
+
+	var tail bytes.Buffer
+	mdatHeader := make([]byte, 8)
+	binary.BigEndian.PutUint32(mdatHeader[0:4], uint32(8+len(sampleData)))
+	copy(mdatHeader[4:8], "mdat")
+	chunkOffset := uint64(written + int64(len(mdatHeader)))
+	tail.Write(mdatHeader)
+	tail.Write(sampleData)
+
+	nextTrackID := uint32(1)
+	for _, trak := range moov.all("trak") {
+		if err = relocateChunkOffsets(trak, spans, newStarts); err != nil {
+			return nil, err
+		}
+		if id := trackID(trak); id >= nextTrackID {
+			nextTrackID = id + 1
+		}
+	}
+
+	var totalDuration uint64
+	for _, d := range durations {
+		totalDuration += uint64(d)
+	}
+
+	moov.children = append(moov.children, movTextTrak(movTextTrack{
+		id:             nextTrackID,
+		language:       language,
+		width:          width,
+		height:         height,
+		duration:       totalDuration,
+		movieDuration:  totalDuration * uint64(movieTimescale) / movTextTimescale,
+		sampleSizes:    sizes,
+		sampleDeltas:   durations,
+		chunkOffset:    chunkOffset,
+		chunkIsLarge64: chunkOffset > math.MaxUint32,
+	}))
+	setNextTrackID(mvhd.payload, nextTrackID+1)
+
+	moov.writeTo(&tail)
+
+	return append(segments, domain.MediaSegment{Data: tail.Bytes()}), nil
+}
+
+// readMP4Span reads the header of the top-level box at offset of a file of size bytes.
+func readMP4Span(r io.ReaderAt, offset, size int64) (mp4Span, error) {
+	header := make([]byte, min(16, size-offset))
+	if err := readFullAt(r, header, offset); err != nil {
+		return mp4Span{}, err
+	}
+
+	typ, boxSize, headerSize, err := readMP4Header(header, size-offset)
+	if err != nil {
+		return mp4Span{}, err
+	}
+
+	span := mp4Span{typ: typ, start: offset, size: boxSize, headerSize: headerSize}
+	if boxSize == 0 {
+		span.size = size - offset
+		span.toEOF = true
+	}
+	return span, nil
+}
+
+// scanMP4 lists the top-level boxes of the file, rejecting truncated or overlapping
+// boxes and fragmented files, whose many moof boxes the muxer does not rewrite.
+func scanMP4(r io.ReaderAt, size int64) ([]mp4Span, error) {
+	var spans []mp4Span
+	for offset := int64(0); offset < size; {
+		span, err := readMP4Span(r, offset, size)
+		if err != nil {
+			return nil, err
+		}
+		if span.typ == "moof" {
+			return nil, ErrUnsupportedMP4
+		}
+		spans = append(spans, span)
+		offset += span.size
+	}
+	return spans, nil
+}
+
+// readMoov reads the movie box at span and parses its children.
+func readMoov(r io.ReaderAt, span mp4Span) ([]*mp4Box, error) {
+	if span.size-span.headerSize > maxMoovSize {
+		return nil, ErrUnsupportedMP4
+	}
+
+	payload := make([]byte, span.size-span.headerSize)
+	if err := readFullAt(r, payload, span.start+span.headerSize); err != nil {
+		return nil, err
+	}
+
+	return parseMP4Boxes(payload)
+}
+
+// parseMP4Boxes parses data into boxes, descending into the containers the muxer edits.
+func parseMP4Boxes(data []byte) ([]*mp4Box, error) {
+	var boxes []*mp4Box
+	for offset := int64(0); offset < int64(len(data)); {
+		typ, size, headerSize, err := readMP4Header(data[offset:], int64(len(data))-offset)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			size = int64(len(data)) - offset
+		}
+
+		box := &mp4Box{typ: typ}
+		payload := data[offset+headerSize : offset+size]
+		if mp4Containers[typ] {
+			if box.children, err = parseMP4Boxes(payload); err != nil {
+				return nil, err
+			}
+		} else {
+			box.payload = payload
+		}
+		boxes = append(boxes, box)
+		offset += size
+	}
+	return boxes, nil
+}
+
+// readMP4Header reads a box header from data, where remaining bytes are left in the
+// enclosing box or file. It returns size 0 for a box that runs to the end.
+func readMP4Header(data []byte, remaining int64) (string, int64, int64, error) {
+	if len(data) < 8 {
+		return "", 0, 0, ErrUnsupportedMP4
+	}
+
+	size := int64(binary.BigEndian.Uint32(data[0:4]))
+	typ := string(data[4:8])
+	headerSize := int64(8)
+	if size == 1 {
+		if len(data) < 16 {
+			return "", 0, 0, ErrUnsupportedMP4
+		}
+		size = int64(binary.BigEndian.Uint64(data[8:16]))
+		headerSize = 16
+	}
+	if size != 0 && (size < headerSize || size > remaining) {
+		return "", 0, 0, ErrUnsupportedMP4
+	}
+	return typ, size, headerSize, nil
+}
+
+func (b *mp4Box) child(typ string) *mp4Box {
+	for _, c := range b.children {
+		if c.typ == typ {
+			return c
+		}
+	}
+	return nil
+}
+
+func (b *mp4Box) all(typ string) []*mp4Box {
+	var boxes []*mp4Box
+	for _, c := range b.children {
+		if c.typ == typ {
+			boxes = append(boxes, c)
+		}
+	}
+	return boxes
+}
+
+func (b *mp4Box) size() int64 {
+	size := int64(8 + len(b.payload))
+	for _, c := range b.children {
+		size += c.size()
+	}
+	if size > math.MaxUint32 {
+		size += 8
+	}
+	return size
+}
+
+func (b *mp4Box) writeTo(buf *bytes.Buffer) {
+	size := b.size()
+	if size > math.MaxUint32 {
+		header := make([]byte, 16)
+		binary.BigEndian.PutUint32(header[0:4], 1)
+		copy(header[4:8], b.typ)
+		binary.BigEndian.PutUint64(header[8:16], uint64(size))
+		buf.Write(header)
+	} else {
+		header := make([]byte, 8)
+		binary.BigEndian.PutUint32(header[0:4], uint32(size))
+		copy(header[4:8], b.typ)
+		buf.Write(header)
+	}
+
+	buf.Write(b.payload)
+	for _, c := range b.children {
+		c.writeTo(buf)
+	}
+}
+
+// readMvhd returns the movie timescale and duration, rejecting a zero timescale.
+func readMvhd(payload []byte) (uint32, uint64, error) {
+	var timescale uint32
+	var duration uint64
+	switch {
+	case len(payload) >= 32 && payload[0] == 1:
+		timescale, duration = binary.BigEndian.Uint32(payload[20:24]), binary.BigEndian.Uint64(payload[24:32])
+	case len(payload) >= 20 && payload[0] == 0:
+		timescale, duration = binary.BigEndian.Uint32(payload[12:16]), uint64(binary.BigEndian.Uint32(payload[16:20]))
+	default:
+		return 0, 0, ErrUnsupportedMP4
+	}
+
+	if timescale == 0 {
+		return 0, 0, ErrUnsupportedMP4
+	}
+	return timescale, duration, nil
+}
+
+// videoDimensions returns the 16.16 fixed-point width and height of the first video track.
+func videoDimensions(moov *mp4Box) (uint32, uint32) {
+	for _, trak := range moov.all("trak") {
+		tkhd, mdia := trak.child("tkhd"), trak.child("mdia")
+		if tkhd == nil || mdia == nil {
+			continue
+		}
+		if hdlr := mdia.child("hdlr"); hdlr == nil || len(hdlr.payload) < 12 || string(hdlr.payload[8:12]) != "vide" {
+			continue
+		}
+
+		offset := 76
+		if len(tkhd.payload) > 0 && tkhd.payload[0] == 1 {
+			offset = 88
+		}
+		if len(tkhd.payload) >= offset+8 {
+			return binary.BigEndian.Uint32(tkhd.payload[offset : offset+4]), binary.BigEndian.Uint32(tkhd.payload[offset+4 : offset+8])
+		}
+	}
+	return 0, 0
+}
+
+// setNextTrackID writes next_track_ID, the last field of the movie header.
+func setNextTrackID(payload []byte, id uint32) {
+	if len(payload) >= 4 {
+		binary.BigEndian.PutUint32(payload[len(payload)-4:], id)
+	}
+}
+
+func trackID(trak *mp4Box) uint32 {
+	tkhd := trak.child("tkhd")
+	if tkhd == nil || len(tkhd.payload) < 24 {
+		return 0
+	}
+	if tkhd.payload[0] == 1 {
+		return binary.BigEndian.Uint32(tkhd.payload[20:24])
+	}
+	return binary.BigEndian.Uint32(tkhd.payload[12:16])
+}
+
+// relocateChunkOffsets maps the stco/co64 entries of trak from the source layout to the
+// output layout, upgrading stco to co64 when an offset no longer fits 32 bits.
+func relocateChunkOffsets(trak *mp4Box, spans []mp4Span, newStarts map[int]int64) error {
+	mdia := trak.child("mdia")
+	if mdia == nil || mdia.child("minf") == nil || mdia.child("minf").child("stbl") == nil {
+		return nil
+	}
+	stbl := mdia.child("minf").child("stbl")
+
+	for _, box := range stbl.children {
+		if box.typ != "stco" && box.typ != "co64" {
+			continue
+		}
+		if len(box.payload) < 8 {
+			return ErrUnsupportedMP4
+		}
+
+		width := 4
+		if box.typ == "co64" {
+			width = 8
+		}
+		count := int(binary.BigEndian.Uint32(box.payload[4:8]))
+		if len(box.payload) < 8+count*width {
+			return ErrUnsupportedMP4
+		}
+
+		offsets := make([]uint64, count)
+		large := box.typ == "co64"
+		for i := range offsets {
+			entry := box.payload[8+i*width:]
+			var offset uint64
+			if width == 8 {
+				offset = binary.BigEndian.Uint64(entry)
+			} else {
+				offset = uint64(binary.BigEndian.Uint32(entry))
+			}
+
+			moved := false
+			for j, span := range spans {
+				newStart, ok := newStarts[j]
+				if ok && offset >= uint64(span.start) && offset < uint64(span.start+span.size) {
+					offset = offset - uint64(span.start) + uint64(newStart)
+					moved = true
+					break
+				}
+			}
+			if !moved {
+				return ErrUnsupportedMP4
+			}
+			offsets[i] = offset
+			large = large || offset > math.MaxUint32
+		}
+
+		payload := append([]byte(nil), box.payload[:8]...)
+		for _, offset := range offsets {
+			if large {
+				payload = binary.BigEndian.AppendUint64(payload, offset)
+			} else {
+				payload = binary.BigEndian.AppendUint32(payload, uint32(offset))
+			}
+		}
+		if large {
+			box.typ = "co64"
+		}
+		box.payload = payload
+	}
+	return nil
+}
+
+// buildTextSamples lays captions out on a gapless timeline as mov_text requires, filling
+// gaps with empty samples. It returns the sample data with each sample's duration in
+// movTextTimescale ticks and size in bytes, or nil data when no caption is left.
+func buildTextSamples(samples []TextSample, movieLength time.Duration) ([]byte, []uint32, []uint32) {
+	sorted := append([]TextSample(nil), samples...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	var data []byte
+	var durations, sizes []uint32
+	var cursor uint32
+	add := func(text string, until uint32) {
+		text = strings.ToValidUTF8(text, "")
+		if len(text) > math.MaxUint16 {
+			text = strings.ToValidUTF8(text[:math.MaxUint16], "")
+		}
+		data = binary.BigEndian.AppendUint16(data, uint16(len(text)))
+		data = append(data, text...)
+		durations = append(durations, until-cursor)
+		sizes = append(sizes, uint32(2+len(text)))
+		cursor = until
+	}
+
+	end := uint32(movieLength.Milliseconds())
+	captions := 0
+	for _, sample := range sorted {
+		start := uint32(max(sample.Start.Milliseconds(), 0))
+		stop := uint32(min(max(sample.End.Milliseconds(), 0), int64(end)))
+		start = max(start, cursor)
+		if stop <= start || strings.TrimSpace(sample.Text) == "" {
+			continue
+		}
+		if start > cursor {
+			add("", start)
+		}
+		add(sample.Text, stop)
+		captions++
+	}
+	if captions == 0 {
+		return nil, nil, nil
+	}
+	if cursor < end {
+		add("", end)
+	}
+
+	return data, durations, sizes
+}
+
+type movTextTrack struct {
+	id             uint32
+	language       string
+	width, height  uint32 // 16.16 fixed point, taken from the video track
+	duration       uint64 // in movTextTimescale
+	movieDuration  uint64 // in the movie timescale
+	sampleSizes    []uint32
+	sampleDeltas   []uint32
+	chunkOffset    uint64
+	chunkIsLarge64 bool
+}
+
+// movTextTrak builds the trak box of a single-chunk tx3g subtitle track.
+func movTextTrak(track movTextTrack) *mp4Box {
+	return &mp4Box{typ: "trak", children: []*mp4Box{
+		{typ: "tkhd", payload: tkhdPayload(track)},
+		{typ: "mdia", children: []*mp4Box{
+			{typ: "mdhd", payload: mdhdPayload(track)},
+			{typ: "hdlr", payload: hdlrPayload()},
+			{typ: "minf", children: []*mp4Box{
+				{typ: "nmhd", payload: make([]byte, 4)},
+				{typ: "dinf", payload: drefBox()},
+				{typ: "stbl", children: []*mp4Box{
+					{typ: "stsd", payload: stsdPayload()},
+					{typ: "stts", payload: sttsPayload(track.sampleDeltas)},
+					{typ: "stsc", payload: stscPayload(len(track.sampleSizes))},
+					{typ: "stsz", payload: stszPayload(track.sampleSizes)},
+					chunkOffsetBox(track),
+				}},
+			}},
+		}},
+	}}
+}
+
+func tkhdPayload(track movTextTrack) []byte {
+	var p []byte
+	if track.movieDuration > math.MaxUint32 {
+		p = append(p, 1, 0, 0, 3) // version 1, enabled | in movie
+		p = binary.BigEndian.AppendUint64(p, 0)
+		p = binary.BigEndian.AppendUint64(p, 0)
+		p = binary.BigEndian.AppendUint32(p, track.id)
+		p = binary.BigEndian.AppendUint32(p, 0)
+		p = binary.BigEndian.AppendUint64(p, track.movieDuration)
+	} else {
+		p = append(p, 0, 0, 0, 3)
+		p = binary.BigEndian.AppendUint32(p, 0)
+		p = binary.BigEndian.AppendUint32(p, 0)
+		p = binary.BigEndian.AppendUint32(p, track.id)
+		p = binary.BigEndian.AppendUint32(p, 0)
+		p = binary.BigEndian.AppendUint32(p, uint32(track.movieDuration))
+	}
+	p = append(p, make([]byte, 8)...)       // reserved
+	p = binary.BigEndian.AppendUint16(p, 0) // layer
+	p = binary.BigEndian.AppendUint16(p, 0) // alternate group
+	p = binary.BigEndian.AppendUint16(p, 0) // volume
+	p = binary.BigEndian.AppendUint16(p, 0) // reserved
+	for _, v := range []uint32{0x00010000, 0, 0, 0, 0x00010000, 0, 0, 0, 0x40000000} {
+		p = binary.BigEndian.AppendUint32(p, v)
+	}
+	p = binary.BigEndian.AppendUint32(p, track.width)
+	p = binary.BigEndian.AppendUint32(p, track.height)
+	return p
+}
+
+func mdhdPayload(track movTextTrack) []byte {
+	var p []byte
+	if track.duration > math.MaxUint32 {
+		p = append(p, 1, 0, 0, 0)
+		p = binary.BigEndian.AppendUint64(p, 0)
+		p = binary.BigEndian.AppendUint64(p, 0)
+		p = binary.BigEndian.AppendUint32(p, movTextTimescale)
+		p = binary.BigEndian.AppendUint64(p, track.duration)
+	} else {
+		p = append(p, 0, 0, 0, 0)
+		p = binary.BigEndian.AppendUint32(p, 0)
+		p = binary.BigEndian.AppendUint32(p, 0)
+		p = binary.BigEndian.AppendUint32(p, movTextTimescale)
+		p = binary.BigEndian.AppendUint32(p, uint32(track.duration))
+	}
+	p = binary.BigEndian.AppendUint16(p, packedLanguage(track.language))
+	return binary.BigEndian.AppendUint16(p, 0)
+}
+
+// packedLanguage packs an ISO 639-2/T code into three 5-bit letters, defaulting to "und".
+func packedLanguage(language string) uint16 {
+	code := strings.ToLower(language)
+	if mapped, ok := iso6392[code]; ok {
+		code = mapped
+	}
+	if len(code) != 3 || strings.IndexFunc(code, func(r rune) bool { return r < 'a' || r > 'z' }) >= 0 {
+		code = "und"
+	}
+	return uint16(code[0]-0x60)<<10 | uint16(code[1]-0x60)<<5 | uint16(code[2]-0x60)
+}
+
+func hdlrPayload() []byte {
+	p := make([]byte, 8)
+	p = append(p, "sbtl"...)
+	p = append(p, make([]byte, 12)...)
+	return append(p, "SubtitleHandler\x00"...)
+}
+
+// drefBox returns a data reference box with one self-contained "url " entry.
+func drefBox() []byte {
+	var buf bytes.Buffer
+	(&mp4Box{typ: "dref", children: []*mp4Box{{typ: "url ", payload: []byte{0, 0, 0, 1}}}, payload: []byte{0, 0, 0, 0, 0, 0, 0, 1}}).writeTo(&buf)
+	return buf.Bytes()
+}
+
+// stsdPayload describes a tx3g sample entry: bottom-centred white text, no default box.
+func stsdPayload() []byte {
+	var entry []byte
+	entry = append(entry, make([]byte, 6)...)              // reserved
+	entry = binary.BigEndian.AppendUint16(entry, 1)        // data reference index
+	entry = binary.BigEndian.AppendUint32(entry, 0)        // display flags
+	entry = append(entry, 1, 0xff)                         // horizontal centre, vertical bottom
+	entry = append(entry, 0, 0, 0, 0)                      // background colour
+	entry = append(entry, make([]byte, 8)...)              // default text box
+	entry = binary.BigEndian.AppendUint16(entry, 0)        // style start char
+	entry = binary.BigEndian.AppendUint16(entry, 0)        // style end char
+	entry = binary.BigEndian.AppendUint16(entry, 1)        // font ID
+	entry = append(entry, 0, 18, 0xff, 0xff, 0xff, 0xff)   // face flags, font size, text colour
+	entry = append(entry, 0, 0, 0, 18, 'f', 't', 'a', 'b') // font table box
+	entry = binary.BigEndian.AppendUint16(entry, 1)
+	entry = binary.BigEndian.AppendUint16(entry, 1)
+	entry = append(entry, 5)
+	entry = append(entry, "Serif"...)
+
+	var buf bytes.Buffer
+	(&mp4Box{typ: "tx3g", payload: entry}).writeTo(&buf)
+
+	p := []byte{0, 0, 0, 0, 0, 0, 0, 1}
+	return append(p, buf.Bytes()...)
+}
+
+func sttsPayload(deltas []uint32) []byte {
+	var counts, values []uint32
+	for _, delta := range deltas {
+		if n := len(values); n > 0 && values[n-1] == delta {
+			counts[n-1]++
+			continue
+		}
+		counts = append(counts, 1)
+		values = append(values, delta)
+	}
+
+	p := binary.BigEndian.AppendUint32(make([]byte, 4), uint32(len(values)))
+	for i := range values {
+		p = binary.BigEndian.AppendUint32(p, counts[i])
+		p = binary.BigEndian.AppendUint32(p, values[i])
+	}
+	return p
+}
+
+func stscPayload(samples int) []byte {
+	p := binary.BigEndian.AppendUint32(make([]byte, 4), 1)
+	p = binary.BigEndian.AppendUint32(p, 1)
+	p = binary.BigEndian.AppendUint32(p, uint32(samples))
+	return binary.BigEndian.AppendUint32(p, 1)
+}
+
+func stszPayload(sizes []uint32) []byte {
+	p := binary.BigEndian.AppendUint32(make([]byte, 4), 0)
+	p = binary.BigEndian.AppendUint32(p, uint32(len(sizes)))
+	for _, size := range sizes {
+		p = binary.BigEndian.AppendUint32(p, size)
+	}
+	return p
+}
+
+func chunkOffsetBox(track movTextTrack) *mp4Box {
+	p := binary.BigEndian.AppendUint32(make([]byte, 4), 1)
+	if track.chunkIsLarge64 {
+		return &mp4Box{typ: "co64", payload: binary.BigEndian.AppendUint64(p, track.chunkOffset)}
+	}
+	return &mp4Box{typ: "stco", payload: binary.BigEndian.AppendUint32(p, uint32(track.chunkOffset))}
+}
@@ -0,0 +1,191 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/alfg/mp4"
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+)
+
+func testBox(typ string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	box := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+	box = append(box, typ...)
+	return append(box, body...)
+}
+
+// testMP4 builds a fast-start file (ftyp, moov, mdat) with one video track whose single
+// chunk is media, and returns it with the offset of that chunk.
+func testMP4(media []byte) ([]byte, int64) {
+	ftyp := testBox("ftyp", []byte("isom\x00\x00\x02\x00isomiso2"))
+
+	mvhd := append([]byte{0, 0, 0, 0}, make([]byte, 8)...)
+	mvhd = binary.BigEndian.AppendUint32(mvhd, 1000)  // timescale
+	mvhd = binary.BigEndian.AppendUint32(mvhd, 10000) // duration
+	mvhd = append(mvhd, make([]byte, 76)...)
+	mvhd = binary.BigEndian.AppendUint32(mvhd, 2) // next track ID
+
+	tkhd := append([]byte{0, 0, 0, 3}, make([]byte, 8)...)
+	tkhd = binary.BigEndian.AppendUint32(tkhd, 1) // track ID
+	tkhd = append(tkhd, make([]byte, 4)...)
+	tkhd = binary.BigEndian.AppendUint32(tkhd, 10000)
+	tkhd = append(tkhd, make([]byte, 52)...)
+	tkhd = binary.BigEndian.AppendUint32(tkhd, 640<<16)
+	tkhd = binary.BigEndian.AppendUint32(tkhd, 360<<16)
+
+	mdhd := append([]byte{0, 0, 0, 0}, make([]byte, 8)...)
+	mdhd = binary.BigEndian.AppendUint32(mdhd, 1000)
+	mdhd = binary.BigEndian.AppendUint32(mdhd, 10000)
+	mdhd = append(mdhd, 0x55, 0xc4, 0, 0)
+
+	hdlr := append(make([]byte, 8), "vide"...)
+	hdlr = append(hdlr, make([]byte, 13)...)
+
+	build := func(chunkOffset uint32) []byte {
+		stco := binary.BigEndian.AppendUint32(make([]byte, 4), 1)
+		stco = binary.BigEndian.AppendUint32(stco, chunkOffset)
+
+		moov := testBox("moov",
+			testBox("mvhd", mvhd),
+			testBox("trak",
+				testBox("tkhd", tkhd),
+				testBox("mdia",
+					testBox("mdhd", mdhd),
+					testBox("hdlr", hdlr),
+					testBox("minf", testBox("stbl", testBox("stco", stco))),
+				),
+			),
+		)
+		return bytes.Join([][]byte{ftyp, moov, testBox("mdat", media)}, nil)
+	}
+
+	chunkOffset := int64(len(build(0)) - len(media))
+	return build(uint32(chunkOffset)), chunkOffset
+}
+
+// assembleSegments writes segments the way the S3 upload does, reading source ranges from src.
+func assembleSegments(t *testing.T, src io.ReaderAt, segments []domain.MediaSegment) []byte {
+	t.Helper()
+
+	var out bytes.Buffer
+	for _, segment := range segments {
+		if segment.Data != nil {
+			out.Write(segment.Data)
+			continue
+		}
+		if _, err := io.Copy(&out, io.NewSectionReader(src, segment.SourceOffset, segment.SourceLength)); err != nil {
+			t.Fatalf("reading source range: %v", err)
+		}
+	}
+	return out.Bytes()
+}
+
+func chunkOffsets(t *testing.T, trak *mp4Box) []uint64 {
+	t.Helper()
+
+	stbl := trak.child("mdia").child("minf").child("stbl")
+	for _, box := range stbl.children {
+		var offsets []uint64
+		count := int(binary.BigEndian.Uint32(box.payload[4:8]))
+		switch box.typ {
+		case "stco":
+			for i := 0; i < count; i++ {
+				offsets = append(offsets, uint64(binary.BigEndian.Uint32(box.payload[8+i*4:])))
+			}
+			return offsets
+		case "co64":
+			for i := 0; i < count; i++ {
+				offsets = append(offsets, binary.BigEndian.Uint64(box.payload[8+i*8:]))
+			}
+			return offsets
+		}
+	}
+	t.Fatal("track has no chunk offset box")
+	return nil
+}
+
+func TestEmbedMP4SubtitlesRoundTrip(t *testing.T) {
+	media := bytes.Repeat([]byte("frame"), 64)
+	source, sourceOffset := testMP4(media)
+	src := bytes.NewReader(source)
+
+	samples := []TextSample{
+		{Start: time.Second, End: 3 * time.Second, Text: "Hello there"},
+		{Start: 5 * time.Second, End: 7 * time.Second, Text: "General Kenobi"},
+	}
+
+	segments, err := EmbedMP4Subtitles(src, int64(len(source)), samples, "en")
+	if err != nil {
+		t.Fatalf("EmbedMP4Subtitles: %v", err)
+	}
+	output := assembleSegments(t, src, segments)
+
+	file, err := mp4.OpenFromBytes(output)
+	if err != nil {
+		t.Fatalf("parsing output: %v", err)
+	}
+	if file.Moov == nil || file.Moov.Mvhd == nil {
+		t.Fatal("output has no movie header")
+	}
+	if file.Moov.Mvhd.Timescale != 1000 || file.Moov.Mvhd.Duration != 10000 {
+		t.Errorf("movie header changed: timescale %d, duration %d", file.Moov.Mvhd.Timescale, file.Moov.Mvhd.Duration)
+	}
+	if len(file.Moov.Traks) != 2 {
+		t.Fatalf("got %d tracks, want 2", len(file.Moov.Traks))
+	}
+
+	video, text := file.Moov.Traks[0], file.Moov.Traks[1]
+	if video.Mdia.Hdlr.Handler != "vide" || text.Mdia.Hdlr.Handler != "sbtl" {
+		t.Errorf("handlers = %q, %q; want vide, sbtl", video.Mdia.Hdlr.Handler, text.Mdia.Hdlr.Handler)
+	}
+	if text.Tkhd.TrackID != 2 {
+		t.Errorf("subtitle track ID = %d, want 2", text.Tkhd.TrackID)
+	}
+	if text.Tkhd.Width != video.Tkhd.Width || text.Tkhd.Height != video.Tkhd.Height {
+		t.Errorf("subtitle track is %vx%v, want the video size %vx%v", text.Tkhd.Width, text.Tkhd.Height, video.Tkhd.Width, video.Tkhd.Height)
+	}
+
+	moov, err := parseMP4Boxes(output[file.Moov.Start+8 : file.Moov.Start+file.Moov.Size])
+	if err != nil {
+		t.Fatalf("parsing output moov: %v", err)
+	}
+	traks := (&mp4Box{children: moov}).all("trak")
+
+	videoOffsets := chunkOffsets(t, traks[0])
+	if len(videoOffsets) != 1 || videoOffsets[0] == uint64(sourceOffset) {
+		t.Fatalf("video chunk offsets = %v, want one offset moved from %d", videoOffsets, sourceOffset)
+	}
+	if got := output[videoOffsets[0] : videoOffsets[0]+uint64(len(media))]; !bytes.Equal(got, media) {
+		t.Error("relocated video chunk offset does not point at the media data")
+	}
+
+	textOffsets := chunkOffsets(t, traks[1])
+	if len(textOffsets) != 1 {
+		t.Fatalf("subtitle chunk offsets = %v, want one chunk", textOffsets)
+	}
+	// The timeline starts with an empty sample up to the first caption.
+	chunk := output[textOffsets[0]:]
+	if length := binary.BigEndian.Uint16(chunk); length != 0 {
+		t.Fatalf("first subtitle sample has %d bytes of text, want the empty gap sample", length)
+	}
+	chunk = chunk[2:]
+	length := binary.BigEndian.Uint16(chunk)
+	if got := string(chunk[2 : 2+length]); got != "Hello there" {
+		t.Errorf("first caption = %q, want %q", got, "Hello there")
+	}
+}
+
+func TestEmbedMP4SubtitlesRejectsZeroTimescale(t *testing.T) {
+	source, _ := testMP4([]byte("frame"))
+	mvhd := bytes.Index(source, []byte("mvhd"))
+	binary.BigEndian.PutUint32(source[mvhd+4+12:], 0)
+
+	_, err := EmbedMP4Subtitles(bytes.NewReader(source), int64(len(source)), []TextSample{{End: time.Second, Text: "hi"}}, "en")
+	if err != ErrUnsupportedMP4 {
+		t.Fatalf("err = %v, want ErrUnsupportedMP4", err)
+	}
+}
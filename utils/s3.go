@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ObjectKeyFromURL returns the object key of an S3 URL that points into bucket, either
+// virtual-hosted (https://bucket.s3.region.amazonaws.com/key) or path style
+// (https://s3.region.amazonaws.com/bucket/key). URLs of any other host or bucket are
+// rejected with ErrNotS3URL, since we could not read or delete those objects.
+func ObjectKeyFromURL(s3URL, bucket string) (string, error) {
+	parsed, err := url.Parse(s3URL)
+	if err != nil {
+		return "", err
+	}
+
+	if parsed.Scheme != "https" && parsed.Scheme != "http" {
+		return "", ErrNotS3URL
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	if !strings.HasSuffix(host, ".amazonaws.com") && !strings.HasSuffix(host, ".amazonaws.com.cn") {
+		return "", ErrNotS3URL
+	}
+
+	path := strings.TrimPrefix(parsed.Path, "/")
+	var key string
+	switch {
+	case strings.HasPrefix(host, bucket+".s3.") || strings.HasPrefix(host, bucket+".s3-"):
+		key = path
+	case strings.HasPrefix(host, "s3.") || strings.HasPrefix(host, "s3-"):
+		var found bool
+		if key, found = strings.CutPrefix(path, bucket+"/"); !found {
+			return "", ErrNotS3URL
+		}
+	}
+
+	if bucket == "" || key == "" {
+		return "", ErrNotS3URL
+	}
+
+	return key, nil
+}
@@ -0,0 +1,88 @@
+package subtitle
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const assHeader = `[Script Info]
+ScriptType: v4.00+
+PlayResX: 1920
+PlayResY: 1080
+WrapStyle: 0
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+`
+
+const assStyle = "Style: %s,Arial,48,%s,&H000000FF,&H00000000,&H64000000,0,0,0,0,100,100,0,0,1,2,1,2,60,60,50,1\n"
+
+const assEvents = `
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+`
+
+// assSpeakerColours cycles through distinguishable primary colours (&HAABBGGRR) per speaker.
+var assSpeakerColours = []string{"&H0000FFFF", "&H00FFFF00", "&H0000FF00", "&H00FF00FF", "&H000080FF", "&H00FF8080"}
+
+func RenderASS(cues []Cue) []byte {
+	var b strings.Builder
+	b.WriteString(assHeader)
+	fmt.Fprintf(&b, assStyle, "Default", "&H00FFFFFF")
+
+	// Every speaker gets its own style so players and editors can tell them apart. Names
+	// that only differ in stripped characters would share a style, so those get a suffix.
+	styles := map[string]string{}
+	taken := map[string]bool{"default": true}
+	for _, cue := range cues {
+		if cue.Speaker == "" {
+			continue
+		}
+		if _, ok := styles[cue.Speaker]; !ok {
+			name := assField(cue.Speaker)
+			for n := 2; taken[strings.ToLower(name)]; n++ {
+				name = fmt.Sprintf("%s %d", assField(cue.Speaker), n)
+			}
+			taken[strings.ToLower(name)] = true
+			styles[cue.Speaker] = name
+			fmt.Fprintf(&b, assStyle, name, assSpeakerColours[(len(styles)-1)%len(assSpeakerColours)])
+		}
+	}
+
+	b.WriteString(assEvents)
+	for _, cue := range cues {
+		style, name := "Default", ""
+		if cue.Speaker != "" {
+			style, name = styles[cue.Speaker], assField(cue.Speaker)
+		}
+		fmt.Fprintf(&b, "Dialogue: 0,%s,%s,%s,%s,0,0,0,,%s\n", formatASSTimestamp(cue.Start), formatASSTimestamp(cue.End), style, name, assTextEscaper.Replace(cue.Text))
+	}
+	return []byte(b.String())
+}
+
+// assField makes value safe for a non-final ASS field: the comma separator is dropped,
+// and so are override braces and backslashes, which some renderers honour in names too.
+func assField(value string) string {
+	value = strings.TrimSpace(assFieldReplacer.Replace(value))
+	if value == "" {
+		return "Speaker"
+	}
+	return value
+}
+
+// assTextEscaper keeps dialogue text from being read as markup: braces are escaped so
+// they cannot open override tags, and a backslash is followed by a word joiner so it
+// never starts an escape such as \N. Line breaks become hard breaks.
+var assTextEscaper = strings.NewReplacer("\\", "\\\u2060", "{", `\{`, "}", `\}`, "\n", `\N`)
+
+var assFieldReplacer = strings.NewReplacer(",", " ", "{", "", "}", "", "\\", "")
+
+// formatASSTimestamp renders h:mm:ss.cc; ASS only has centisecond precision.
+func formatASSTimestamp(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	cs := d.Milliseconds() / 10
+	return fmt.Sprintf("%d:%02d:%02d.%02d", cs/360000, cs/6000%60, cs/100%60, cs%100)
+}
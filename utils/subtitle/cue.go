@@ -0,0 +1,150 @@
+package subtitle
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
+)
+
+// Cue is a single timed subtitle entry. Lines are joined with "\n" in Text and
+// Speaker holds the display name of the speaker, if known.
+type Cue struct {
+	Start   time.Duration
+	End     time.Duration
+	Text    string
+	Speaker string
+}
+
+// Options mirror the conversion params the transcription backend applies.
+type Options struct {
+	WordsPerLine        int
+	Punctuation         bool
+	ConsiderPunctuation bool
+	LineBreak           domain.LineBreakSettings
+	Speakers            map[string]string // diarization label -> display name; nil disables labels
+}
+
+// BuildCues groups transcript words into cues. In words mode a cue holds at most
+// WordsPerLine words; chars mode is handled by buildCharCues. When ConsiderPunctuation
+// is set a cue also ends after a word carrying punctuation.
+func BuildCues(segments []domain.TranscriptSegment, opts Options) []Cue {
+	if opts.LineBreak.Mode == types.CharsLineBreak {
+		return buildCharCues(segments, opts)
+	}
+
+	if opts.WordsPerLine < 1 {
+		opts.WordsPerLine = 1
+	}
+
+	var cues []Cue
+	var current []domain.TranscriptWord
+	var speaker string
+
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+
+		texts := make([]string, 0, len(current))
+		for _, word := range current {
+			text := word.Text
+			if !opts.Punctuation {
+				text = stripPunctuation(text)
+			}
+			if text != "" {
+				texts = append(texts, text)
+			}
+		}
+
+		if len(texts) > 0 {
+			cues = append(cues, Cue{
+				Start:   seconds(current[0].Start),
+				End:     seconds(current[len(current)-1].End),
+				Text:    strings.Join(texts, " "),
+				Speaker: speaker,
+			})
+		}
+		current = current[:0]
+	}
+
+	for _, segment := range segments {
+		speaker = opts.Speakers[segment.Speaker]
+		for _, word := range segmentWords(segment) {
+			current = append(current, word)
+			if len(current) >= opts.WordsPerLine || (opts.ConsiderPunctuation && endsWithPunctuation(word.Text)) {
+				flush()
+			}
+		}
+		// Never let a cue span two transcript segments.
+		flush()
+	}
+
+	return cues
+}
+
+// PlainText returns the cue text with its speaker prefix, as written to formats
+// without speaker markup.
+func (c Cue) PlainText() string {
+	return speakerPrefix(c, true) + c.Text
+}
+
+// DefaultSpeakerNames names diarization labels "Speaker 1", "Speaker 2", ... in order
+// of first appearance.
+func DefaultSpeakerNames(segments []domain.TranscriptSegment) map[string]string {
+	names := map[string]string{}
+	for _, segment := range segments {
+		if segment.Speaker == "" {
+			continue
+		}
+		if _, ok := names[segment.Speaker]; !ok {
+			names[segment.Speaker] = fmt.Sprintf("Speaker %d", len(names)+1)
+		}
+	}
+	return names
+}
+
+// segmentWords returns the word timings of a segment, spreading the segment duration
+// over its words by length when the backend did not provide them.
+func segmentWords(segment domain.TranscriptSegment) []domain.TranscriptWord {
+	if len(segment.Words) > 0 {
+		return segment.Words
+	}
+
+	fields := strings.Fields(segment.Text)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	total := 0
+	for _, field := range fields {
+		total += len([]rune(field))
+	}
+
+	words := make([]domain.TranscriptWord, 0, len(fields))
+	span := segment.End - segment.Start
+	start := segment.Start
+	for _, field := range fields {
+		end := start + span*float64(len([]rune(field)))/float64(total)
+		words = append(words, domain.TranscriptWord{Start: start, End: end, Text: field})
+		start = end
+	}
+
+	return words
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value*float64(time.Second) + 0.5)
+}
+
+func endsWithPunctuation(text string) bool {
+	runes := []rune(strings.TrimSpace(text))
+	return len(runes) > 0 && unicode.IsPunct(runes[len(runes)-1])
+}
+
+func stripPunctuation(text string) string {
+	return strings.TrimFunc(text, unicode.IsPunct)
+}
@@ -0,0 +1,196 @@
+package subtitle
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
+)
+
+// TranscriptCues converts rendered cues to their stored form. Speaker is copied as is,
+// so cues should be built with labels rather than display names.
+func TranscriptCues(cues []Cue) []domain.TranscriptCue {
+	stored := make([]domain.TranscriptCue, len(cues))
+	for i, cue := range cues {
+		stored[i] = domain.TranscriptCue{
+			Start:   cue.Start.Seconds(),
+			End:     cue.End.Seconds(),
+			Text:    cue.Text,
+			Speaker: cue.Speaker,
+		}
+	}
+	return stored
+}
+
+// CuesFromTranscript converts stored cues for rendering, resolving speaker labels to
+// display names. A nil speakers map drops the labels.
+func CuesFromTranscript(stored []domain.TranscriptCue, speakers map[string]string) []Cue {
+	cues := make([]Cue, len(stored))
+	for i, cue := range stored {
+		cues[i] = Cue{
+			Start:   seconds(cue.Start),
+			End:     seconds(cue.End),
+			Text:    cue.Text,
+			Speaker: speakers[cue.Speaker],
+		}
+	}
+	return cues
+}
+
+// CueSegments turns stored cues back into transcript segments, one per cue, so edited
+// cues can be re-rendered with other settings. The segments carry no word timings;
+// BuildCues spreads each cue's text over its timing again.
+func CueSegments(cues []domain.TranscriptCue) []domain.TranscriptSegment {
+	segments := make([]domain.TranscriptSegment, len(cues))
+	for i, cue := range cues {
+		segments[i] = domain.TranscriptSegment{
+			Start:   cue.Start,
+			End:     cue.End,
+			Text:    strings.Join(strings.Fields(cue.Text), " "),
+			Speaker: cue.Speaker,
+		}
+	}
+	return segments
+}
+
+// ApplyCueOperations applies ops in order to a copy of cues. Cues stay sorted by start
+// time, retimed cues do not overlap their neighbours, and every cue keeps a positive
+// duration and non-empty text.
+func ApplyCueOperations(cues []domain.TranscriptCue, ops []domain.CueOperation) ([]domain.TranscriptCue, error) {
+	edited := append([]domain.TranscriptCue(nil), cues...)
+
+	for i, op := range ops {
+		var err error
+		if op.Index < 0 || op.Index >= len(edited) {
+			return nil, fmt.Errorf("operation %d: cue index %d out of range", i+1, op.Index)
+		}
+
+		switch op.Op {
+		case types.TextCueOperation:
+			err = editCueText(edited, op)
+		case types.TimingCueOperation:
+			err = editCueTiming(edited, op)
+		case types.SplitCueOperation:
+			edited, err = splitCue(edited, op)
+		case types.MergeCueOperation:
+			edited, err = mergeCues(edited, op)
+		default:
+			err = fmt.Errorf("op must be one of text, timing, split or merge")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i+1, err)
+		}
+	}
+
+	return edited, nil
+}
+
+func editCueText(cues []domain.TranscriptCue, op domain.CueOperation) error {
+	if op.Text == nil || strings.TrimSpace(*op.Text) == "" {
+		return fmt.Errorf("text is required")
+	}
+	cues[op.Index].Text = strings.TrimSpace(*op.Text)
+	return nil
+}
+
+func editCueTiming(cues []domain.TranscriptCue, op domain.CueOperation) error {
+	if op.Start == nil && op.End == nil {
+		return fmt.Errorf("start or end is required")
+	}
+
+	cue := cues[op.Index]
+	if op.Start != nil {
+		cue.Start = *op.Start
+	}
+	if op.End != nil {
+		cue.End = *op.End
+	}
+
+	if cue.Start < 0 || cue.End <= cue.Start {
+		return fmt.Errorf("start must be at least 0 and before end")
+	}
+	if op.Index > 0 && cue.Start < cues[op.Index-1].End {
+		return fmt.Errorf("start cannot overlap the previous cue")
+	}
+	if op.Index < len(cues)-1 && cue.End > cues[op.Index+1].Start {
+		return fmt.Errorf("end cannot overlap the next cue")
+	}
+
+	cues[op.Index] = cue
+	return nil
+}
+
+func splitCue(cues []domain.TranscriptCue, op domain.CueOperation) ([]domain.TranscriptCue, error) {
+	cue := cues[op.Index]
+	if op.At == nil || *op.At <= cue.Start || *op.At >= cue.End {
+		return nil, fmt.Errorf("at must fall inside the cue")
+	}
+
+	runes := []rune(cue.Text)
+	var position int
+	if op.Position != nil {
+		position = *op.Position
+	} else {
+		position = nearestWordBoundary(runes, int(math.Round(float64(len(runes))*(*op.At-cue.Start)/(cue.End-cue.Start))))
+	}
+	if position <= 0 || position >= len(runes) {
+		return nil, fmt.Errorf("position must fall inside the cue text")
+	}
+
+	first, second := strings.TrimSpace(string(runes[:position])), strings.TrimSpace(string(runes[position:]))
+	if first == "" || second == "" {
+		return nil, fmt.Errorf("split would leave an empty cue")
+	}
+
+	split := make([]domain.TranscriptCue, 0, len(cues)+1)
+	split = append(split, cues[:op.Index]...)
+	split = append(split,
+		domain.TranscriptCue{Start: cue.Start, End: *op.At, Text: first, Speaker: cue.Speaker},
+		domain.TranscriptCue{Start: *op.At, End: cue.End, Text: second, Speaker: cue.Speaker},
+	)
+	return append(split, cues[op.Index+1:]...), nil
+}
+
+func mergeCues(cues []domain.TranscriptCue, op domain.CueOperation) ([]domain.TranscriptCue, error) {
+	if op.Index == len(cues)-1 {
+		return nil, fmt.Errorf("the last cue has nothing to merge with")
+	}
+
+	first, second := cues[op.Index], cues[op.Index+1]
+	if first.Speaker != "" && second.Speaker != "" && first.Speaker != second.Speaker {
+		return nil, fmt.Errorf("cues of different speakers cannot be merged")
+	}
+	if first.Speaker == "" {
+		first.Speaker = second.Speaker
+	}
+	first.End = max(first.End, second.End)
+	first.Text = first.Text + " " + second.Text
+
+	merged := append(cues[:op.Index:op.Index], first)
+	return append(merged, cues[op.Index+2:]...), nil
+}
+
+// nearestWordBoundary returns the space closest to target, or target itself when the
+// text has no spaces.
+func nearestWordBoundary(runes []rune, target int) int {
+	best := -1
+	for i, r := range runes {
+		if unicode.IsSpace(r) && (best < 0 || abs(i-target) < abs(best-target)) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return target
+	}
+	return best
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
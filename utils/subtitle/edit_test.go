@@ -0,0 +1,59 @@
+package subtitle
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
+)
+
+func TestEditedCuesSurviveRerender(t *testing.T) {
+	segments := []domain.TranscriptSegment{
+		{Start: 0, End: 2, Text: "helo wrld", Speaker: "SPEAKER_00"},
+		{Start: 3, End: 5, Text: "second line", Speaker: "SPEAKER_01"},
+	}
+	labels := map[string]string{"SPEAKER_00": "SPEAKER_00", "SPEAKER_01": "SPEAKER_01"}
+	cues := TranscriptCues(BuildCues(segments, Options{WordsPerLine: 10, Speakers: labels}))
+
+	text, start := "hello world", 0.5
+	edited, err := ApplyCueOperations(cues, []domain.CueOperation{
+		{Op: types.TextCueOperation, Index: 0, Text: &text},
+		{Op: types.TimingCueOperation, Index: 0, Start: &start},
+	})
+	if err != nil {
+		t.Fatalf("ApplyCueOperations: %v", err)
+	}
+
+	speakers := map[string]string{"SPEAKER_00": "Ada", "SPEAKER_01": "Bob"}
+	rendered := BuildCues(CueSegments(edited), Options{WordsPerLine: 1, Speakers: speakers})
+
+	want := []Cue{
+		{Start: 500 * time.Millisecond, End: 1250 * time.Millisecond, Text: "hello", Speaker: "Ada"},
+		{Start: 1250 * time.Millisecond, End: 2 * time.Second, Text: "world", Speaker: "Ada"},
+		{Start: 3 * time.Second, End: 4200 * time.Millisecond, Text: "second", Speaker: "Bob"},
+		{Start: 4200 * time.Millisecond, End: 5 * time.Second, Text: "line", Speaker: "Bob"},
+	}
+	if len(rendered) != len(want) {
+		t.Fatalf("got %d cues, want %d: %+v", len(rendered), len(want), rendered)
+	}
+	for i := range want {
+		if rendered[i] != want[i] {
+			t.Errorf("cue %d = %+v, want %+v", i, rendered[i], want[i])
+		}
+	}
+
+	srt, _, err := Render(types.SRTFormat, rendered)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if strings.Contains(string(srt), "helo") || strings.Contains(string(srt), "wrld") {
+		t.Errorf("re-rendered file still has the original text:\n%s", srt)
+	}
+
+	shifted := AdjustSegments(CueSegments(edited), domain.TimingAdjustment{Operation: types.ShiftTiming, Offset: 1})
+	if shifted[0].Start != 1.5 || shifted[0].Text != "hello world" {
+		t.Errorf("retimed cue = %+v, want the edited cue shifted to 1.5s", shifted[0])
+	}
+}
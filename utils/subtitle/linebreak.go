@@ -0,0 +1,112 @@
+package subtitle
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+)
+
+// buildCharCues fills each cue line up to MaxCharsPerLine and each cue up to
+// MaxLinesPerCue lines or MaxCueDuration, then stretches cue timings towards the
+// minimum duration and reading speed targets without overlapping the next cue.
+func buildCharCues(segments []domain.TranscriptSegment, opts Options) []Cue {
+	settings := opts.LineBreak
+	maxDuration := seconds(settings.MaxCueDuration)
+
+	var cues []Cue
+	var lines []string
+	var start, end time.Duration
+	var speaker string
+
+	flush := func() {
+		if len(lines) > 0 {
+			cues = append(cues, Cue{Start: start, End: end, Text: strings.Join(lines, "\n"), Speaker: speaker})
+			lines = nil
+		}
+	}
+
+	for _, segment := range segments {
+		speaker = opts.Speakers[segment.Speaker]
+		for _, word := range segmentWords(segment) {
+			text := word.Text
+			if !opts.Punctuation {
+				text = stripPunctuation(text)
+			}
+			if text == "" {
+				continue
+			}
+
+			fitsLine := false
+			if len(lines) > 0 {
+				last := lines[len(lines)-1]
+				fitsLine = settings.MaxCharsPerLine <= 0 ||
+					utf8.RuneCountInString(last)+1+utf8.RuneCountInString(text) <= settings.MaxCharsPerLine
+
+				lineCount := len(lines)
+				if !fitsLine {
+					lineCount++
+				}
+				tooLong := maxDuration > 0 && seconds(word.End)-start > maxDuration
+				if (settings.MaxLinesPerCue > 0 && lineCount > settings.MaxLinesPerCue) || tooLong {
+					flush()
+					fitsLine = false
+				}
+			}
+
+			switch {
+			case len(lines) == 0:
+				lines = []string{text}
+				start = seconds(word.Start)
+			case fitsLine:
+				lines[len(lines)-1] += " " + text
+			default:
+				lines = append(lines, text)
+			}
+			end = seconds(word.End)
+
+			if opts.ConsiderPunctuation && endsWithPunctuation(word.Text) {
+				flush()
+			}
+		}
+		flush()
+	}
+
+	adjustTimings(cues, settings)
+	return cues
+}
+
+// adjustTimings extends cue ends to honour MinCueDuration and MaxCPS. A cue is never
+// shortened, never pushed past MaxCueDuration and never made to overlap the next cue.
+func adjustTimings(cues []Cue, settings domain.LineBreakSettings) {
+	for i := range cues {
+		cue := &cues[i]
+		target := cue.End
+
+		if minEnd := cue.Start + seconds(settings.MinCueDuration); minEnd > target {
+			target = minEnd
+		}
+
+		if settings.MaxCPS > 0 {
+			chars := utf8.RuneCountInString(strings.ReplaceAll(cue.Text, "\n", ""))
+			if cpsEnd := cue.Start + seconds(float64(chars)/settings.MaxCPS); cpsEnd > target {
+				target = cpsEnd
+			}
+		}
+
+		if settings.MaxCueDuration > 0 {
+			if maxEnd := cue.Start + seconds(settings.MaxCueDuration); target > maxEnd {
+				target = maxEnd
+			}
+		}
+
+		if i+1 < len(cues) && target > cues[i+1].Start {
+			target = cues[i+1].Start
+		}
+
+		if target > cue.End {
+			cue.End = target
+		}
+	}
+}
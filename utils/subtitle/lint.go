@@ -0,0 +1,173 @@
+package subtitle
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
+)
+
+// IsLintableFormat reports whether Lint can check subtitles of format.
+func IsLintableFormat(format types.SubtitleFormat) bool {
+	return format == types.SRTFormat || format == types.VTTFormat
+}
+
+type linter struct {
+	opts   domain.LintOptions
+	report *domain.LintReport
+}
+
+func (l *linter) add(cue, line int, code, severity, format string, args ...any) {
+	l.report.Diagnostics = append(l.report.Diagnostics, domain.LintDiagnostic{
+		Cue:      cue,
+		Line:     line,
+		Code:     code,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Lint checks an SRT or WebVTT file and reports problems per cue instead of stopping
+// at the first one.
+func Lint(format types.SubtitleFormat, data []byte, opts domain.LintOptions) *domain.LintReport {
+	l := &linter{opts: opts, report: &domain.LintReport{Format: format, Diagnostics: []domain.LintDiagnostic{}}}
+
+	l.checkEncoding(data)
+
+	content := string(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	blocks := splitBlocks(content)
+
+	if format == types.VTTFormat {
+		if len(blocks) == 0 || !strings.HasPrefix(blocks[0].lines[0], "WEBVTT") {
+			l.add(0, 1, "missing_header", domain.LintError, "file must start with a WEBVTT header")
+		} else {
+			blocks = blocks[1:]
+		}
+	}
+
+	var previous *Cue
+	previousIndex := 0
+	for _, b := range blocks {
+		if format == types.VTTFormat && isVTTMetadataBlock(b.lines) {
+			continue
+		}
+
+		l.report.CueCount++
+		position := l.report.CueCount
+
+		timing := 0
+		if !strings.Contains(b.lines[0], "-->") {
+			timing = 1
+			if format == types.SRTFormat {
+				index, err := strconv.Atoi(strings.TrimSpace(b.lines[0]))
+				switch {
+				case err != nil:
+					l.add(position, b.line, "invalid_index", domain.LintError, "cue index %q is not a number", b.lines[0])
+				case index != previousIndex+1:
+					l.add(position, b.line, "index_out_of_order", domain.LintWarning, "cue index %d follows %d", index, previousIndex)
+				}
+				if err == nil {
+					previousIndex = index
+				}
+			}
+		}
+
+		if timing >= len(b.lines) {
+			l.add(position, b.line, "missing_timing", domain.LintError, "cue has no timing line")
+			continue
+		}
+
+		start, end, err := parseTimingLine(b.lines[timing])
+		if err != nil {
+			l.add(position, b.line+timing, "invalid_timing", domain.LintError, "%s", err.Error())
+			continue
+		}
+
+		cue := Cue{Start: start, End: end, Text: strings.Join(b.lines[timing+1:], "\n")}
+		l.checkCue(position, b.line+timing, cue, previous)
+		previous = &cue
+	}
+
+	l.report.Valid = true
+	for _, diagnostic := range l.report.Diagnostics {
+		if diagnostic.Severity == domain.LintError {
+			l.report.Valid = false
+			break
+		}
+	}
+
+	return l.report
+}
+
+func (l *linter) checkCue(position, timingLine int, cue Cue, previous *Cue) {
+	duration := cue.End - cue.Start
+	switch {
+	case duration < 0:
+		l.add(position, timingLine, "negative_duration", domain.LintError, "cue ends %s before it starts", -duration)
+	case duration == 0:
+		l.add(position, timingLine, "zero_duration", domain.LintWarning, "cue starts and ends at the same time")
+	}
+
+	if previous != nil {
+		if cue.Start < previous.Start {
+			l.add(position, timingLine, "timing_out_of_order", domain.LintError, "cue starts before the previous cue")
+		} else if cue.Start < previous.End {
+			l.add(position, timingLine, "overlap", domain.LintWarning, "cue overlaps the previous cue by %s", previous.End-cue.Start)
+		}
+	}
+
+	if strings.TrimSpace(cue.Text) == "" {
+		l.add(position, timingLine, "empty_cue", domain.LintWarning, "cue has no text")
+		return
+	}
+
+	chars := 0
+	for i, line := range strings.Split(cue.Text, "\n") {
+		length := utf8.RuneCountInString(line)
+		chars += length
+		if l.opts.MaxCharsPerLine > 0 && length > l.opts.MaxCharsPerLine {
+			l.add(position, timingLine+1+i, "line_too_long", domain.LintWarning, "line has %d characters, limit is %d", length, l.opts.MaxCharsPerLine)
+		}
+	}
+
+	if l.opts.MaxCPS > 0 && duration > 0 {
+		cps := float64(chars) / (float64(duration) / float64(time.Second))
+		if cps > l.opts.MaxCPS {
+			l.add(position, timingLine, "reading_speed", domain.LintWarning, "reading speed is %.1f characters per second, limit is %.1f", cps, l.opts.MaxCPS)
+		}
+	}
+}
+
+// checkEncoding reports invalid UTF-8, replacement characters left over from a bad
+// transcode and stray control characters, by line.
+func (l *linter) checkEncoding(data []byte) {
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		number := i + 1
+		if !utf8.Valid(line) {
+			l.add(0, number, "invalid_encoding", domain.LintError, "line is not valid UTF-8")
+			continue
+		}
+
+		text := strings.TrimSuffix(string(line), "\r")
+		if i == 0 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		for _, r := range text {
+			if r == utf8.RuneError {
+				l.add(0, number, "replacement_character", domain.LintWarning, "line contains U+FFFD, the file was probably decoded with the wrong charset")
+				break
+			}
+			if unicode.IsControl(r) && r != '\t' {
+				l.add(0, number, "control_character", domain.LintWarning, "line contains control character %U", r)
+				break
+			}
+		}
+	}
+}
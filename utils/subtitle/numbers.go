@@ -0,0 +1,166 @@
+package subtitle
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+)
+
+var numberUnits = map[string]int64{
+	"zero": 0, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "seven": 7,
+	"eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12, "thirteen": 13,
+	"fourteen": 14, "fifteen": 15, "sixteen": 16, "seventeen": 17, "eighteen": 18,
+	"nineteen": 19, "twenty": 20, "thirty": 30, "forty": 40, "fifty": 50, "sixty": 60,
+	"seventy": 70, "eighty": 80, "ninety": 90,
+}
+
+var numberScales = map[string]int64{
+	"thousand": 1_000, "million": 1_000_000, "billion": 1_000_000_000,
+}
+
+// spelledNumber accumulates the words of one spelled-out number. Words are only
+// accepted where they form a valid compound: a tens word followed by a unit, "X
+// hundred" and descending scale words, so "ten eleven" is two numbers, not 21.
+type spelledNumber struct {
+	total   int64 // completed scale groups, e.g. 2000 in "two thousand five"
+	group   int64 // the group below the last scale, 0-999
+	scale   int64 // last scale word used, 0 before any
+	hundred bool  // group already has "hundred"
+	closed  bool  // group ends in a unit or teen, so nothing below 100 can follow
+	tens    bool  // group ends in a tens word that a unit may complete
+	zero    bool
+	words   int
+}
+
+func (n spelledNumber) value() int64 {
+	return n.total + n.group
+}
+
+// add returns n extended by a lower-cased, possibly hyphenated word, and whether the
+// word continues the number.
+func (n spelledNumber) add(word string) (spelledNumber, bool) {
+	for _, part := range strings.Split(word, "-") {
+		var ok bool
+		if n, ok = n.addPart(part); !ok {
+			return n, false
+		}
+	}
+	n.words++
+	return n, true
+}
+
+func (n spelledNumber) addPart(part string) (spelledNumber, bool) {
+	if n.zero {
+		return n, false
+	}
+
+	if part == "hundred" {
+		if n.hundred || n.group == 0 || n.group >= 100 {
+			return n, false
+		}
+		n.group *= 100
+		n.hundred, n.closed, n.tens = true, false, false
+		return n, true
+	}
+
+	if scale, ok := numberScales[part]; ok {
+		if n.group == 0 || (n.scale > 0 && scale >= n.scale) {
+			return n, false
+		}
+		n.total += n.group * scale
+		n.group, n.scale = 0, scale
+		n.hundred, n.closed, n.tens = false, false, false
+		return n, true
+	}
+
+	value, ok := numberUnits[part]
+	if !ok {
+		return n, false
+	}
+
+	switch {
+	case part == "zero":
+		if n.words > 0 || n.group > 0 || n.total > 0 {
+			return n, false
+		}
+		n.zero = true
+	case value >= 20:
+		if n.closed || n.tens || n.group%100 != 0 {
+			return n, false
+		}
+		n.group += value
+		n.tens = true
+	case value >= 10:
+		if n.closed || n.tens || n.group%100 != 0 {
+			return n, false
+		}
+		n.group += value
+		n.closed = true
+	default:
+		if n.closed || (!n.tens && n.group%100 != 0) {
+			return n, false
+		}
+		n.group += value
+		n.closed, n.tens = true, false
+	}
+	return n, true
+}
+
+// NormalizeNumbers turns spelled-out English numbers into digits, e.g. "twenty five"
+// into "25". Adjacent numbers stay separate, so "ten eleven" becomes "10 11". Numbers
+// below ten stay spelled out, following common subtitle style.
+func NormalizeNumbers() Processor {
+	return tokenProcessor(func(words []domain.TranscriptWord) []domain.TranscriptWord {
+		var normalized []domain.TranscriptWord
+		for i := 0; i < len(words); {
+			leading, core, trailing := splitToken(words[i].Text)
+			number, ok := spelledNumber{}.add(strings.ToLower(core))
+			if core == "" || !ok {
+				normalized = append(normalized, words[i])
+				i++
+				continue
+			}
+
+			// Extend the number while words continue it; "and" is allowed inside a number
+			// as in "one hundred and five". Punctuation ends the number.
+			// A group started after a scale word is remembered, so that "one thousand one
+			// thousand" gives back the second "one" instead of ending as "1001 thousand".
+			end := i + 1
+			var scaled spelledNumber
+			var scaledEnd int
+			for end < len(words) && trailing == "" {
+				next := end
+				if _, c, _ := splitToken(words[next].Text); strings.EqualFold(c, "and") && words[next].Text == c && (number.hundred || number.scale > 0) && next+1 < len(words) {
+					next++
+				}
+
+				l, c, t := splitToken(words[next].Text)
+				lower := strings.ToLower(c)
+				extended, ok := number.add(lower)
+				if l != "" || c == "" || !ok {
+					if _, isScale := numberScales[lower]; isScale && l == "" && scaledEnd > 0 && number.group > 0 {
+						number, end = scaled, scaledEnd
+					}
+					break
+				}
+				if number.scale > 0 && number.group == 0 {
+					scaled, scaledEnd = number, end
+				}
+				number, trailing, end = extended, t, next+1
+			}
+
+			if number.value() < 10 {
+				normalized = append(normalized, words[i:end]...)
+			} else {
+				normalized = append(normalized, domain.TranscriptWord{
+					Start: words[i].Start,
+					End:   words[end-1].End,
+					Text:  leading + strconv.FormatInt(number.value(), 10) + trailing,
+				})
+			}
+			i = end
+		}
+		return normalized
+	})
+}
@@ -0,0 +1,59 @@
+package subtitle
+
+import (
+	"testing"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+)
+
+func TestNormalizeNumbers(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"twenty five people", "25 people"},
+		{"twenty-five people", "25 people"},
+		{"one hundred and five", "105"},
+		{"three hundred twelve", "312"},
+		{"two thousand twenty four", "2024"},
+		{"one million two hundred thousand", "1200000"},
+		{"chapters ten eleven twelve", "chapters 10 11 12"},
+		{"in nineteen ninety nine", "in 19 99"},
+		{"count five six seven eight nine ten", "count five six seven eight nine 10"},
+		{"twenty twenty four", "20 24"},
+		{"thousand thousand", "thousand thousand"},
+		{"one thousand one thousand", "1000 1000"},
+		{"eleven, twelve.", "11, 12."},
+		{"rock and roll", "rock and roll"},
+		{"five and twenty", "five and 20"},
+		{"zero zero seven", "zero zero seven"},
+		{"seven", "seven"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			segments, _ := NormalizeNumbers()([]domain.TranscriptSegment{{Text: tt.in}})
+			if got := segments[0].Text; got != tt.want {
+				t.Errorf("NormalizeNumbers(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeNumbersKeepsWordTimings(t *testing.T) {
+	segments, changed := NormalizeNumbers()([]domain.TranscriptSegment{{
+		Text: "ten eleven",
+		Words: []domain.TranscriptWord{
+			{Start: 0, End: 0.4, Text: "ten"},
+			{Start: 0.4, End: 0.9, Text: "eleven"},
+		},
+	}})
+
+	if !changed {
+		t.Fatal("expected the segment to change")
+	}
+	words := segments[0].Words
+	if len(words) != 2 || words[0].Text != "10" || words[1].Text != "11" || words[1].Start != 0.4 {
+		t.Errorf("unexpected words %+v", words)
+	}
+}
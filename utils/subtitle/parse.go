@@ -0,0 +1,340 @@
+package subtitle
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
+)
+
+var parsers = map[types.SubtitleFormat]func(content string) ([]Cue, error){
+	types.SRTFormat: parseSRT,
+	types.VTTFormat: parseVTT,
+	types.ASSFormat: parseASS,
+	types.SBVFormat: parseSBV,
+}
+
+// IsParsableFormat reports whether Parse can read subtitles of format.
+func IsParsableFormat(format types.SubtitleFormat) bool {
+	_, ok := parsers[format]
+	return ok
+}
+
+// FormatFromFileName maps a subtitle file extension to its format; .ssa is read as ASS.
+func FormatFromFileName(fileName string) types.SubtitleFormat {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(fileName)), ".")
+	if ext == "ssa" {
+		return types.ASSFormat
+	}
+	return types.SubtitleFormat(ext)
+}
+
+// Parse reads subtitles of the given format into the common cue model.
+func Parse(format types.SubtitleFormat, data []byte) ([]Cue, error) {
+	parse, ok := parsers[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported subtitle format: %s", format)
+	}
+
+	content := string(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\r", "\n")
+
+	return parse(content)
+}
+
+type block struct {
+	line  int // 1-based line number of the first line
+	lines []string
+}
+
+func splitBlocks(content string) []block {
+	var blocks []block
+	var current block
+	for i, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) == "" {
+			if len(current.lines) > 0 {
+				blocks = append(blocks, current)
+				current = block{}
+			}
+			continue
+		}
+		if len(current.lines) == 0 {
+			current.line = i + 1
+		}
+		current.lines = append(current.lines, strings.TrimRight(line, " \t"))
+	}
+	if len(current.lines) > 0 {
+		blocks = append(blocks, current)
+	}
+	return blocks
+}
+
+// parseTimingLine reads "start --> end", ignoring WebVTT cue settings after end.
+func parseTimingLine(line string) (time.Duration, time.Duration, error) {
+	parts := strings.SplitN(line, "-->", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid timing line %q", line)
+	}
+
+	start, err := parseTimestamp(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	fields := strings.Fields(parts[1])
+	if len(fields) == 0 {
+		return 0, 0, fmt.Errorf("invalid timing line %q", line)
+	}
+
+	end, err := parseTimestamp(fields[0])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return start, end, nil
+}
+
+// parseTimestamp accepts [hh:]mm:ss followed by a "," or "." and 1-3 fraction digits,
+// which covers SRT, WebVTT, SBV and ASS timestamps.
+func parseTimestamp(value string) (time.Duration, error) {
+	invalid := fmt.Errorf("invalid timestamp %q", value)
+
+	clock, fraction := value, ""
+	if i := strings.LastIndexAny(value, ",."); i >= 0 {
+		clock, fraction = value[:i], value[i+1:]
+	}
+
+	parts := strings.Split(clock, ":")
+	if len(parts) < 2 || len(parts) > 3 || len(fraction) > 3 {
+		return 0, invalid
+	}
+
+	var total time.Duration
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, invalid
+		}
+		total = total*60 + time.Duration(n)
+	}
+	total *= time.Second
+
+	if fraction != "" {
+		n, err := strconv.Atoi(fraction + strings.Repeat("0", 3-len(fraction)))
+		if err != nil || n < 0 {
+			return 0, invalid
+		}
+		total += time.Duration(n) * time.Millisecond
+	}
+
+	return total, nil
+}
+
+func parseSRT(content string) ([]Cue, error) {
+	var cues []Cue
+	for _, b := range splitBlocks(content) {
+		block := b.lines
+		timing := 0
+		if !strings.Contains(block[0], "-->") {
+			timing = 1
+		}
+		if timing >= len(block) {
+			return nil, fmt.Errorf("cue %q has no timing line", block[0])
+		}
+
+		start, end, err := parseTimingLine(block[timing])
+		if err != nil {
+			return nil, err
+		}
+
+		cues = append(cues, Cue{Start: start, End: end, Text: strings.Join(block[timing+1:], "\n")})
+	}
+	return cues, nil
+}
+
+func parseVTT(content string) ([]Cue, error) {
+	blocks := splitBlocks(content)
+	if len(blocks) == 0 || !strings.HasPrefix(blocks[0].lines[0], "WEBVTT") {
+		return nil, fmt.Errorf("missing WEBVTT header")
+	}
+
+	var cues []Cue
+	for _, b := range blocks[1:] {
+		block := b.lines
+		if isVTTMetadataBlock(block) {
+			continue
+		}
+
+		timing := 0
+		if !strings.Contains(block[0], "-->") {
+			timing = 1
+		}
+		if timing >= len(block) {
+			return nil, fmt.Errorf("cue %q has no timing line", block[0])
+		}
+
+		start, end, err := parseTimingLine(block[timing])
+		if err != nil {
+			return nil, err
+		}
+
+		speaker, text := splitVTTVoice(strings.Join(block[timing+1:], "\n"))
+		cues = append(cues, Cue{Start: start, End: end, Text: text, Speaker: speaker})
+	}
+	return cues, nil
+}
+
+var vttVoiceTag = regexp.MustCompile(`^<v(?:\.[^\s>]*)?\s+([^>]+)>`)
+
+// splitVTTVoice pulls a leading <v Speaker> voice tag off the cue text and decodes the
+// character references in both, undoing the escaping RenderVTT applies.
+func splitVTTVoice(text string) (string, string) {
+	match := vttVoiceTag.FindStringSubmatch(text)
+	if match == nil {
+		return "", html.UnescapeString(text)
+	}
+	text = strings.TrimPrefix(text, match[0])
+	return html.UnescapeString(strings.TrimSpace(match[1])), html.UnescapeString(strings.ReplaceAll(text, "</v>", ""))
+}
+
+func isVTTMetadataBlock(lines []string) bool {
+	return strings.HasPrefix(lines[0], "NOTE") || lines[0] == "STYLE" || lines[0] == "REGION"
+}
+
+func parseSBV(content string) ([]Cue, error) {
+	var cues []Cue
+	for _, b := range splitBlocks(content) {
+		block := b.lines
+		times := strings.SplitN(block[0], ",", 2)
+		if len(times) != 2 {
+			return nil, fmt.Errorf("invalid timing line %q", block[0])
+		}
+
+		start, err := parseTimestamp(strings.TrimSpace(times[0]))
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseTimestamp(strings.TrimSpace(times[1]))
+		if err != nil {
+			return nil, err
+		}
+
+		cues = append(cues, Cue{Start: start, End: end, Text: strings.Join(block[1:], "\n")})
+	}
+	return cues, nil
+}
+
+func parseASS(content string) ([]Cue, error) {
+	var cues []Cue
+	var fields []string
+	inEvents := false
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			inEvents = strings.EqualFold(line, "[Events]")
+			continue
+		}
+		if !inEvents {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "Format":
+			fields = strings.Split(value, ",")
+			for i := range fields {
+				fields[i] = strings.TrimSpace(fields[i])
+			}
+		case "Dialogue":
+			if len(fields) == 0 {
+				return nil, fmt.Errorf("dialogue before events format line")
+			}
+
+			// Text is always the last field and may itself contain commas.
+			values := strings.SplitN(strings.TrimSpace(value), ",", len(fields))
+			if len(values) != len(fields) {
+				return nil, fmt.Errorf("invalid dialogue line %q", line)
+			}
+
+			cue := Cue{}
+			for i, field := range fields {
+				var err error
+				switch field {
+				case "Start":
+					cue.Start, err = parseTimestamp(strings.TrimSpace(values[i]))
+				case "End":
+					cue.End, err = parseTimestamp(strings.TrimSpace(values[i]))
+				case "Name":
+					cue.Speaker = strings.TrimSpace(values[i])
+				case "Text":
+					cue.Text = assPlainText(values[i])
+				}
+				if err != nil {
+					return nil, err
+				}
+			}
+			cues = append(cues, cue)
+		}
+	}
+
+	if fields == nil {
+		return nil, fmt.Errorf("missing [Events] section")
+	}
+	return cues, nil
+}
+
+// assPlainText drops override blocks such as {\i1} and turns ASS line breaks into newlines.
+// The escapes RenderASS writes are decoded in the same pass, before a brace can open a
+// block: \{ and \} are literal braces and a backslash followed by a word joiner is a
+// literal backslash.
+func assPlainText(text string) string {
+	var b strings.Builder
+	runes := []rune(text)
+	depth := 0
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '\\' && i+1 < len(runes) {
+			var decoded string
+			switch runes[i+1] {
+			case '{', '}':
+				decoded = string(runes[i+1])
+			case '\u2060':
+				decoded = `\`
+			case 'N', 'n':
+				decoded = "\n"
+			case 'h':
+				decoded = " "
+			}
+			if decoded != "" {
+				if depth == 0 {
+					b.WriteString(decoded)
+				}
+				i++
+				continue
+			}
+		}
+
+		switch {
+		case r == '{':
+			depth++
+		case r == '}' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,125 @@
+package subtitle
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
+)
+
+func TestParseVTTUnescapesText(t *testing.T) {
+	data := "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\n<v Tom &amp; Jerry>Fish &amp; chips &lt;3 &gt;_&lt;\n\n" +
+		"00:00:03.000 --> 00:00:04.000\nR&amp;D &#38; more\n\n"
+
+	cues, err := Parse(types.VTTFormat, []byte(data))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	want := []Cue{
+		{Start: time.Second, End: 2 * time.Second, Text: "Fish & chips <3 >_<", Speaker: "Tom & Jerry"},
+		{Start: 3 * time.Second, End: 4 * time.Second, Text: "R&D & more"},
+	}
+	if len(cues) != len(want) {
+		t.Fatalf("got %d cues, want %d", len(cues), len(want))
+	}
+	for i := range want {
+		if cues[i] != want[i] {
+			t.Errorf("cue %d = %+v, want %+v", i, cues[i], want[i])
+		}
+	}
+}
+
+func TestVTTRoundTripThroughRenderers(t *testing.T) {
+	source := []Cue{{Start: time.Second, End: 2 * time.Second, Text: "Fish & chips <3", Speaker: "Tom & Jerry"}}
+	vtt, _, err := Render(types.VTTFormat, source)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	cues, err := Parse(types.VTTFormat, vtt)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(cues) != 1 || cues[0] != source[0] {
+		t.Fatalf("VTT round trip = %+v, want %+v", cues, source)
+	}
+
+	// What each format should contain for the parsed cue: escaped once where the format
+	// is markup, literal everywhere else.
+	want := map[types.SubtitleFormat]string{
+		types.SRTFormat:  "TOM & JERRY: Fish & chips <3",
+		types.VTTFormat:  "<v Tom &amp; Jerry>Fish &amp; chips &lt;3",
+		types.ASSFormat:  "Tom & Jerry,0,0,0,,Fish & chips <3",
+		types.TTMLFormat: "Tom &amp; Jerry: Fish &amp; chips &lt;3",
+		types.SBVFormat:  "TOM & JERRY: Fish & chips <3",
+		types.TXTFormat:  "Tom & Jerry: Fish & chips <3",
+	}
+	for format := range renderers {
+		t.Run(string(format), func(t *testing.T) {
+			rendered, _, err := Render(format, cues)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if !strings.Contains(string(rendered), want[format]) {
+				t.Errorf("output does not contain %q:\n%s", want[format], rendered)
+			}
+
+			if !IsParsableFormat(format) {
+				return
+			}
+			parsed, err := Parse(format, rendered)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(parsed) != 1 || !strings.HasSuffix(parsed[0].Text, source[0].Text) {
+				t.Errorf("parsed back %+v, want text ending in %q", parsed, source[0].Text)
+			}
+		})
+	}
+}
+
+func TestASSRoundTripKeepsBracesAndBackslashes(t *testing.T) {
+	texts := []string{
+		"{not an override} and }{",
+		`C:\Users\new\Notes`,
+		`a literal \N and \h and \{`,
+		"two\nlines",
+	}
+
+	source := make([]Cue, len(texts))
+	for i, text := range texts {
+		source[i] = Cue{Start: time.Duration(i) * time.Second, End: time.Duration(i+1) * time.Second, Text: text}
+	}
+
+	rendered, _, err := Render(types.ASSFormat, source)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	cues, err := Parse(types.ASSFormat, rendered)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(cues) != len(source) {
+		t.Fatalf("got %d cues, want %d", len(cues), len(source))
+	}
+	for i := range source {
+		if cues[i].Text != source[i].Text {
+			t.Errorf("cue %d text = %q, want %q", i, cues[i].Text, source[i].Text)
+		}
+	}
+}
+
+func TestParseASSStripsOverrides(t *testing.T) {
+	data := "[Events]\nFormat: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n" +
+		`Dialogue: 0,0:00:01.00,0:00:02.00,Default,,0,0,0,,{\i1}Hello{\i0}\Nthere\hfriend, {\b1}hi` + "\n"
+
+	cues, err := Parse(types.ASSFormat, []byte(data))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(cues) != 1 || cues[0].Text != "Hello\nthere friend, hi" {
+		t.Fatalf("cues = %+v, want one cue with the overrides removed", cues)
+	}
+}
@@ -0,0 +1,157 @@
+package subtitle
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+)
+
+// Processor transforms transcript segments and reports whether anything changed.
+type Processor func(segments []domain.TranscriptSegment) ([]domain.TranscriptSegment, bool)
+
+// Chain runs processors in order, feeding each the output of the previous one.
+func Chain(processors ...Processor) Processor {
+	return func(segments []domain.TranscriptSegment) ([]domain.TranscriptSegment, bool) {
+		changed := false
+		for _, process := range processors {
+			var c bool
+			segments, c = process(segments)
+			changed = changed || c
+		}
+		return segments, changed
+	}
+}
+
+// TextProcessor applies rewrite to whole segment texts, see RewriteSegments.
+func TextProcessor(rewrite func(string) string) Processor {
+	return func(segments []domain.TranscriptSegment) ([]domain.TranscriptSegment, bool) {
+		return RewriteSegments(segments, rewrite)
+	}
+}
+
+// tokenProcessor applies fn to the words of each segment. Segments without word timings
+// are split on whitespace into untimed words first. The segment text is rebuilt from
+// the resulting words.
+func tokenProcessor(fn func(words []domain.TranscriptWord) []domain.TranscriptWord) Processor {
+	return func(segments []domain.TranscriptSegment) ([]domain.TranscriptSegment, bool) {
+		changed := false
+		processed := make([]domain.TranscriptSegment, len(segments))
+
+		for i, segment := range segments {
+			timed := len(segment.Words) > 0
+			words := make([]domain.TranscriptWord, 0, len(segment.Words))
+			if timed {
+				words = append(words, segment.Words...)
+			} else {
+				for _, field := range strings.Fields(segment.Text) {
+					words = append(words, domain.TranscriptWord{Text: field})
+				}
+			}
+
+			words = fn(words)
+
+			texts := make([]string, len(words))
+			for j, word := range words {
+				texts[j] = word.Text
+			}
+			text := strings.Join(texts, " ")
+
+			if text != strings.Join(strings.Fields(segment.Text), " ") {
+				changed = true
+				segment.Text = text
+				if timed {
+					segment.Words = words
+				}
+			}
+			processed[i] = segment
+		}
+
+		return processed, changed
+	}
+}
+
+// splitToken separates leading and trailing punctuation from a word, e.g. `"Um,` into
+// `"`, `Um` and `,`.
+func splitToken(text string) (string, string, string) {
+	core := strings.TrimFunc(text, unicode.IsPunct)
+	if core == "" {
+		return text, "", ""
+	}
+	start := strings.Index(text, core)
+	return text[:start], core, text[start+len(core):]
+}
+
+var fillerWords = map[string]bool{
+	"um": true, "umm": true, "uh": true, "uhh": true, "uh-huh": true, "er": true, "erm": true,
+	"ah": true, "hmm": true, "hm": true, "mm": true, "mhm": true,
+}
+
+// RemoveFillers drops filler words such as "um" and "uh". Sentence-ending punctuation
+// carried by a dropped filler moves to the preceding word, replacing a trailing comma.
+func RemoveFillers() Processor {
+	return tokenProcessor(func(words []domain.TranscriptWord) []domain.TranscriptWord {
+		kept := words[:0]
+		for _, word := range words {
+			_, core, trailing := splitToken(word.Text)
+			if !fillerWords[strings.ToLower(core)] {
+				kept = append(kept, word)
+				continue
+			}
+			if len(kept) > 0 && strings.ContainsAny(trailing, ".?!") {
+				previous := &kept[len(kept)-1]
+				if strings.LastIndexAny(previous.Text, ".?!") != len(previous.Text)-1 {
+					previous.Text = strings.TrimRight(previous.Text, ",;:") + strings.Trim(trailing, ",;:")
+				}
+			}
+		}
+		return kept
+	})
+}
+
+var profanities = map[string]bool{
+	"fuck": true, "fucking": true, "fucked": true, "fucker": true, "motherfucker": true,
+	"shit": true, "shitty": true, "bullshit": true, "bitch": true, "bitches": true,
+	"asshole": true, "assholes": true, "bastard": true, "cunt": true, "dick": true,
+	"dickhead": true, "cock": true, "pussy": true, "piss": true, "pissed": true,
+	"slut": true, "whore": true, "wanker": true, "twat": true, "prick": true,
+}
+
+// MaskProfanity keeps the first letter of profane words and masks the rest, e.g. "f***".
+func MaskProfanity() Processor {
+	return tokenProcessor(func(words []domain.TranscriptWord) []domain.TranscriptWord {
+		for i, word := range words {
+			leading, core, trailing := splitToken(word.Text)
+			if !profanities[strings.ToLower(core)] {
+				continue
+			}
+			first, size := utf8.DecodeRuneInString(core)
+			words[i].Text = leading + string(first) + strings.Repeat("*", utf8.RuneCountInString(core[size:])) + trailing
+		}
+		return words
+	})
+}
+
+// SentenceCase capitalises the first word of every sentence, across segment
+// boundaries, and the pronoun "i". Other casing is left alone so names survive.
+func SentenceCase() Processor {
+	return func(segments []domain.TranscriptSegment) ([]domain.TranscriptSegment, bool) {
+		capitalizeNext := true
+		return tokenProcessor(func(words []domain.TranscriptWord) []domain.TranscriptWord {
+			for i, word := range words {
+				leading, core, trailing := splitToken(word.Text)
+				if core == "" {
+					continue
+				}
+				if capitalizeNext || core == "i" || strings.HasPrefix(core, "i'") {
+					first, size := utf8.DecodeRuneInString(core)
+					core = string(unicode.ToUpper(first)) + core[size:]
+				}
+				words[i].Text = leading + core + trailing
+				capitalizeNext = strings.ContainsAny(trailing, ".?!")
+			}
+			return words
+		})(segments)
+	}
+}
@@ -0,0 +1,46 @@
+package subtitle
+
+import (
+	"fmt"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
+)
+
+type renderer struct {
+	render      func(cues []Cue) []byte
+	contentType string
+}
+
+var renderers = map[types.SubtitleFormat]renderer{
+	types.SRTFormat:  {RenderSRT, "application/x-subrip"},
+	types.VTTFormat:  {RenderVTT, "text/vtt"},
+	types.ASSFormat:  {RenderASS, "text/x-ssa"},
+	types.TTMLFormat: {RenderTTML, "application/ttml+xml"},
+	types.SBVFormat:  {RenderSBV, "text/plain"},
+	types.TXTFormat:  {RenderTXT, "text/plain"},
+}
+
+func IsValidFormat(format types.SubtitleFormat) bool {
+	_, ok := renderers[format]
+	return ok
+}
+
+// Render emits cues in the given format and returns the body with its content type.
+func Render(format types.SubtitleFormat, cues []Cue) ([]byte, string, error) {
+	r, ok := renderers[format]
+	if !ok {
+		return nil, "", fmt.Errorf("unsupported subtitle format: %s", format)
+	}
+	return r.render(cues), r.contentType, nil
+}
+
+// ContentType returns the MIME type of files of format, or an empty string for
+// unsupported formats.
+func ContentType(format types.SubtitleFormat) string {
+	return renderers[format].contentType
+}
+
+// FileExtension returns the extension, including the dot, used for files of format.
+func FileExtension(format types.SubtitleFormat) string {
+	return "." + string(format)
+}
@@ -0,0 +1,114 @@
+package subtitle
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+)
+
+// RewriteSegments applies rewrite to every segment text and reports whether anything
+// changed. Word timings are kept when the word count is unchanged and dropped otherwise,
+// so BuildCues spreads the segment over its new words.
+func RewriteSegments(segments []domain.TranscriptSegment, rewrite func(string) string) ([]domain.TranscriptSegment, bool) {
+	changed := false
+	rewritten := make([]domain.TranscriptSegment, len(segments))
+
+	for i, segment := range segments {
+		text := rewrite(segment.Text)
+		if text != segment.Text {
+			changed = true
+
+			fields := strings.Fields(text)
+			if len(fields) == len(segment.Words) {
+				words := make([]domain.TranscriptWord, len(segment.Words))
+				for j, word := range segment.Words {
+					word.Text = fields[j]
+					words[j] = word
+				}
+				segment.Words = words
+			} else {
+				segment.Words = nil
+			}
+			segment.Text = text
+		}
+		rewritten[i] = segment
+	}
+
+	return rewritten, changed
+}
+
+// GlossaryReplacer returns a rewrite that replaces every alias of a glossary entry, and
+// any differently cased spelling of its term, with the term. The text is scanned once,
+// so a replacement is never rewritten again, and at each position the longest phrase
+// that forms whole words wins.
+func GlossaryReplacer(glossaries []*domain.Glossary) func(string) string {
+	type rule struct {
+		phrase string
+		term   string
+	}
+
+	var rules []rule
+	for _, glossary := range glossaries {
+		rules = append(rules, rule{glossary.Term, glossary.Term})
+		for _, alias := range glossary.Aliases {
+			rules = append(rules, rule{alias, glossary.Term})
+		}
+	}
+	if len(rules) == 0 {
+		return func(text string) string { return text }
+	}
+	sort.SliceStable(rules, func(i, j int) bool { return len(rules[i].phrase) > len(rules[j].phrase) })
+
+	// Each phrase is its own group so the match tells which term to write. The trailing
+	// boundary is part of the pattern, so a phrase followed by a letter falls through to
+	// the next, shorter alternative. The leading boundary needs a lookbehind, which Go
+	// regexp lacks, so it is checked by hand.
+	alternatives := make([]string, len(rules))
+	for i, r := range rules {
+		alternatives[i] = "(" + regexp.QuoteMeta(r.phrase) + ")"
+	}
+	pattern := regexp.MustCompile(`(?i)(?:` + strings.Join(alternatives, "|") + `)(?:$|[^\p{L}\p{N}])`)
+
+	return func(text string) string {
+		var out strings.Builder
+		written, pos := 0, 0
+		for pos < len(text) {
+			match := pattern.FindStringSubmatchIndex(text[pos:])
+			if match == nil {
+				break
+			}
+
+			start := pos + match[0]
+			if before, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isWordRune(before) {
+				_, size := utf8.DecodeRuneInString(text[start:])
+				pos = start + size
+				continue
+			}
+
+			for group := 1; group <= len(rules); group++ {
+				if match[2*group] < 0 {
+					continue
+				}
+				end := pos + match[2*group+1]
+				out.WriteString(text[written:start])
+				out.WriteString(rules[group-1].term)
+				written, pos = end, end
+				break
+			}
+		}
+
+		if written == 0 {
+			return text
+		}
+		out.WriteString(text[written:])
+		return out.String()
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
@@ -0,0 +1,54 @@
+package subtitle
+
+import (
+	"testing"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+)
+
+func TestGlossaryReplacer(t *testing.T) {
+	replace := GlossaryReplacer([]*domain.Glossary{
+		{Term: "SmartSRT", Aliases: []string{"smart srt", "smart s r t"}},
+		{Term: "Kubernetes", Aliases: []string{"k8s"}},
+		{Term: "K8s Operator", Aliases: []string{"kate operator"}},
+		{Term: "C++"},
+		{Term: "$HOME"},
+	})
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"smart srt smart srt, smart srt", "SmartSRT SmartSRT, SmartSRT"},
+		{"Smart SRT and SMARTSRT", "SmartSRT and SmartSRT"},
+		{"smart s r t is longer than smart srt", "SmartSRT is longer than SmartSRT"},
+		{"smart srts are not matched", "smart srts are not matched"},
+		{"nosmart srt", "nosmart srt"},
+		{"we run k8s", "we run Kubernetes"},
+		{"a kate operator for k8s", "a K8s Operator for Kubernetes"},
+		{"k8s operator", "K8s Operator"},
+		{"learn c++ today", "learn C++ today"},
+		{"echo $home", "echo $HOME"},
+		{"çk8s k8sç k8s", "çk8s k8sç Kubernetes"},
+		{"nothing to replace", "nothing to replace"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := replace(tt.in); got != tt.want {
+				t.Errorf("GlossaryReplacer(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlossaryReplacerPrefersLongerPhraseThatEndsOnABoundary(t *testing.T) {
+	replace := GlossaryReplacer([]*domain.Glossary{
+		{Term: "Go", Aliases: []string{"golang"}},
+		{Term: "Go Team", Aliases: []string{"go teamx"}},
+	})
+
+	if got, want := replace("the go teamxy and the go teamx"), "the Go teamxy and the Go Team"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
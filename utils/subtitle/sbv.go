@@ -0,0 +1,24 @@
+package subtitle
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+func RenderSBV(cues []Cue) []byte {
+	var b strings.Builder
+	for _, cue := range cues {
+		fmt.Fprintf(&b, "%s,%s\n%s\n\n", formatSBVTimestamp(cue.Start), formatSBVTimestamp(cue.End), speakerPrefix(cue, true)+cue.Text)
+	}
+	return []byte(b.String())
+}
+
+// formatSBVTimestamp renders h:mm:ss.mmm as used by YouTube.
+func formatSBVTimestamp(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
@@ -0,0 +1,98 @@
+package subtitle
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// snippetRadius is how many runes of context a snippet keeps on each side of the first
+// match in long cue texts.
+const snippetRadius = 60
+
+// SearchTerms splits a search query into lower-cased words, dropping the quotes and
+// negations of MongoDB text search syntax.
+func SearchTerms(query string) []string {
+	var terms []string
+	for _, field := range strings.Fields(strings.ToLower(query)) {
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+		field = strings.TrimFunc(field, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsNumber(r) })
+		if field != "" {
+			terms = append(terms, field)
+		}
+	}
+	return terms
+}
+
+// Highlight returns an HTML-escaped snippet of text with every whole-word occurrence
+// of terms wrapped in <mark>, and whether any term occurred at all. Long texts are cut
+// around the first match.
+func Highlight(text string, terms []string) (string, bool) {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		// Case mapping changed the length; fall back to matching the original text.
+		lower = runes
+	}
+
+	marked := make([]bool, len(runes))
+	first := -1
+	for _, term := range terms {
+		needle := []rune(term)
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if string(lower[i:i+len(needle)]) != term || !wordBoundary(lower, i) || !wordBoundary(lower, i+len(needle)) {
+				continue
+			}
+			for j := i; j < i+len(needle); j++ {
+				marked[j] = true
+			}
+			if first < 0 || i < first {
+				first = i
+			}
+		}
+	}
+	if first < 0 {
+		return "", false
+	}
+
+	start, end := 0, len(runes)
+	if len(runes) > 2*snippetRadius {
+		start = max(first-snippetRadius, 0)
+		end = min(start+2*snippetRadius, len(runes))
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; {
+		j := i
+		for j < end && marked[j] == marked[i] {
+			j++
+		}
+		chunk := html.EscapeString(string(runes[i:j]))
+		if marked[i] {
+			b.WriteString("<mark>" + chunk + "</mark>")
+		} else {
+			b.WriteString(chunk)
+		}
+		i = j
+	}
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+
+	return b.String(), true
+}
+
+// wordBoundary reports whether position i of runes separates a word from what precedes
+// it, matching how the unstemmed text index tokenizes.
+func wordBoundary(runes []rune, i int) bool {
+	if i == 0 || i == len(runes) {
+		return true
+	}
+	isWord := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }
+	return !isWord(runes[i-1]) || !isWord(runes[i])
+}
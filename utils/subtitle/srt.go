@@ -0,0 +1,35 @@
+package subtitle
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+func RenderSRT(cues []Cue) []byte {
+	var b strings.Builder
+	for i, cue := range cues {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1, formatTimestamp(cue.Start, ","), formatTimestamp(cue.End, ","), cue.PlainText())
+	}
+	return []byte(b.String())
+}
+
+// speakerPrefix returns "NAME: " for formats without native speaker markup, e.g. "SPEAKER 1: ".
+func speakerPrefix(cue Cue, upper bool) string {
+	if cue.Speaker == "" {
+		return ""
+	}
+	if upper {
+		return strings.ToUpper(cue.Speaker) + ": "
+	}
+	return cue.Speaker + ": "
+}
+
+// formatTimestamp renders hh:mm:ss<sep>mmm, the layout shared by SRT and WebVTT.
+func formatTimestamp(d time.Duration, sep string) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
@@ -0,0 +1,75 @@
+package subtitle
+
+import (
+	"time"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
+)
+
+// linearMapping returns scale and offset such that t' = scale*t + offset. Every
+// supported operation is linear in time.
+func linearMapping(adjustment domain.TimingAdjustment) (float64, float64) {
+	switch adjustment.Operation {
+	case types.ShiftTiming:
+		return 1, adjustment.Offset
+	case types.StretchTiming:
+		p1, p2 := adjustment.SyncPoints[0], adjustment.SyncPoints[1]
+		scale := (p2.To - p1.To) / (p2.From - p1.From)
+		return scale, p1.To - scale*p1.From
+	case types.FramerateTiming:
+		// Frame numbers stay the same, so times scale by the inverse framerate ratio.
+		return adjustment.FromFPS / adjustment.ToFPS, 0
+	default:
+		return 1, 0
+	}
+}
+
+// AdjustCues retimes cues, clamping at zero and dropping cues that end up entirely
+// before the start of the media.
+func AdjustCues(cues []Cue, adjustment domain.TimingAdjustment) []Cue {
+	scale, offset := linearMapping(adjustment)
+	apply := func(d time.Duration) time.Duration {
+		return max(seconds(scale*d.Seconds()+offset), 0)
+	}
+
+	adjusted := make([]Cue, 0, len(cues))
+	for _, cue := range cues {
+		cue.Start, cue.End = apply(cue.Start), apply(cue.End)
+		if cue.End > 0 {
+			adjusted = append(adjusted, cue)
+		}
+	}
+	return adjusted
+}
+
+// AdjustSegments retimes transcript segments and their words the same way AdjustCues
+// retimes cues.
+func AdjustSegments(segments []domain.TranscriptSegment, adjustment domain.TimingAdjustment) []domain.TranscriptSegment {
+	scale, offset := linearMapping(adjustment)
+	apply := func(t float64) float64 {
+		return max(scale*t+offset, 0)
+	}
+
+	adjusted := make([]domain.TranscriptSegment, 0, len(segments))
+	for _, segment := range segments {
+		segment.Start, segment.End = apply(segment.Start), apply(segment.End)
+		if segment.End <= 0 {
+			continue
+		}
+
+		words := make([]domain.TranscriptWord, 0, len(segment.Words))
+		for _, word := range segment.Words {
+			word.Start, word.End = apply(word.Start), apply(word.End)
+			if word.End > 0 {
+				words = append(words, word)
+			}
+		}
+		if len(segment.Words) > 0 {
+			segment.Words = words
+		}
+
+		adjusted = append(adjusted, segment)
+	}
+	return adjusted
+}
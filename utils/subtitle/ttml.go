@@ -0,0 +1,25 @@
+package subtitle
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+func RenderTTML(cues []Cue) []byte {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<tt xmlns="http://www.w3.org/ns/ttml">` + "\n")
+	b.WriteString("  <body>\n    <div>\n")
+	for _, cue := range cues {
+		lines := strings.Split(speakerPrefix(cue, false)+cue.Text, "\n")
+		for i, line := range lines {
+			var escaped strings.Builder
+			_ = xml.EscapeText(&escaped, []byte(line))
+			lines[i] = escaped.String()
+		}
+		fmt.Fprintf(&b, "      <p begin=\"%s\" end=\"%s\">%s</p>\n", formatTimestamp(cue.Start, "."), formatTimestamp(cue.End, "."), strings.Join(lines, "<br/>"))
+	}
+	b.WriteString("    </div>\n  </body>\n</tt>\n")
+	return []byte(b.String())
+}
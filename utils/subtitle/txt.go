@@ -0,0 +1,13 @@
+package subtitle
+
+import "strings"
+
+// RenderTXT emits the plain transcript, one cue per line and without timings.
+func RenderTXT(cues []Cue) []byte {
+	var b strings.Builder
+	for _, cue := range cues {
+		b.WriteString(speakerPrefix(cue, false) + strings.ReplaceAll(cue.Text, "\n", " "))
+		b.WriteString("\n")
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,22 @@
+package subtitle
+
+import (
+	"fmt"
+	"strings"
+)
+
+// vttEscaper escapes the characters WebVTT reads as markup in cue text and annotations.
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func RenderVTT(cues []Cue) []byte {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for _, cue := range cues {
+		text := vttEscaper.Replace(cue.Text)
+		if cue.Speaker != "" {
+			text = fmt.Sprintf("<v %s>%s", vttEscaper.Replace(cue.Speaker), text)
+		}
+		fmt.Fprintf(&b, "%s --> %s\n%s\n\n", formatTimestamp(cue.Start, "."), formatTimestamp(cue.End, "."), text)
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+const TusVersion = "1.0.0"
+
+// ParseTusMetadata decodes an Upload-Metadata header ("key base64value,key2 base64value2").
+func ParseTusMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		parts := strings.Fields(pair)
+		switch len(parts) {
+		case 1:
+			metadata[parts[0]] = ""
+		case 2:
+			value, err := base64.StdEncoding.DecodeString(parts[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid metadata value for %s", parts[0])
+			}
+			metadata[parts[0]] = string(value)
+		default:
+			return nil, fmt.Errorf("invalid metadata pair: %s", pair)
+		}
+	}
+
+	return metadata, nil
+}
@@ -0,0 +1,142 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
+	"github.com/kwa0x2/SmartSRT-Backend/utils/subtitle"
+)
+
+const (
+	DefaultHistoryPageSize = 20
+	MaxHistoryPageSize     = 100
+)
+
+var historySortFields = map[string]string{
+	"created_at": "created_at",
+	"file_name":  "file_name",
+	"duration":   "duration",
+}
+
+// ParseHistoryQuery reads the filters, sort and page of a history listing. Dates are
+// RFC 3339 timestamps or YYYY-MM-DD days; a day in "to" includes the whole day. Only a
+// limit or cursor turns on pagination; without them every matching history is listed.
+func ParseHistoryQuery(value func(key string) string) (*domain.HistoryQuery, error) {
+	query := &domain.HistoryQuery{
+		Page: domain.PageRequest{
+			SortField:  "created_at",
+			Descending: true,
+			Limit:      DefaultHistoryPageSize,
+			Cursor:     value("cursor"),
+		},
+		Paginated: value("limit") != "" || value("cursor") != "",
+	}
+
+	if sort := value("sort"); sort != "" {
+		field, ok := historySortFields[strings.ToLower(sort)]
+		if !ok {
+			return nil, fmt.Errorf("sort must be one of created_at, file_name or duration")
+		}
+		query.Page.SortField = field
+		// Names read naturally A to Z; dates and durations largest first.
+		query.Page.Descending = field != "file_name"
+	}
+
+	switch strings.ToLower(value("order")) {
+	case "":
+	case "asc":
+		query.Page.Descending = false
+	case "desc":
+		query.Page.Descending = true
+	default:
+		return nil, fmt.Errorf("order must be asc or desc")
+	}
+
+	if limit := value("limit"); limit != "" {
+		val, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil || val < 1 || val > MaxHistoryPageSize {
+			return nil, fmt.Errorf("limit must be between 1 and %d", MaxHistoryPageSize)
+		}
+		query.Page.Limit = val
+	}
+
+	var err error
+	if query.From, err = parseHistoryDate("from", value("from"), false); err != nil {
+		return nil, err
+	}
+	if query.To, err = parseHistoryDate("to", value("to"), true); err != nil {
+		return nil, err
+	}
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		return nil, fmt.Errorf("from must be before to")
+	}
+
+	if query.MinDuration, err = parseHistoryDuration("min_duration", value("min_duration")); err != nil {
+		return nil, err
+	}
+	if query.MaxDuration, err = parseHistoryDuration("max_duration", value("max_duration")); err != nil {
+		return nil, err
+	}
+	if query.MinDuration != nil && query.MaxDuration != nil && *query.MinDuration > *query.MaxDuration {
+		return nil, fmt.Errorf("min_duration cannot be greater than max_duration")
+	}
+
+	query.FileName = strings.TrimSpace(value("file_name"))
+	if len(query.FileName) > 255 {
+		return nil, fmt.Errorf("file_name cannot be longer than 255 characters")
+	}
+
+	if format := value("format"); format != "" {
+		query.Format = types.SubtitleFormat(strings.ToLower(format))
+		if !subtitle.IsValidFormat(query.Format) {
+			return nil, fmt.Errorf("format must be one of srt, vtt, ass, ttml, sbv or txt")
+		}
+	}
+
+	if language := value("language"); language != "" {
+		if query.Language, err = ParseLanguage("language", language, false); err != nil {
+			return nil, err
+		}
+	}
+
+	return query, nil
+}
+
+// parseHistoryDate returns the start of the given instant or day. With endOfDay set a
+// bare day moves to the start of the next day, so that it can be used as an exclusive bound.
+func parseHistoryDate(field, value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		t = t.UTC()
+		return &t, nil
+	}
+
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC 3339 timestamp or a YYYY-MM-DD date", field)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+func parseHistoryDuration(field, value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	val, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(val) || math.IsInf(val, 0) || val < 0 {
+		return nil, fmt.Errorf("%s must be a non-negative number of seconds", field)
+	}
+	return &val, nil
+}
@@ -0,0 +1,50 @@
+package validator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const AutoLanguage = "auto"
+
+var iso6391Codes = strings.Fields(`
+aa ab ae af ak am an ar as av ay az ba be bg bh bi bm bn bo br bs ca ce ch co cr cs cu cv cy
+da de dv dz ee el en eo es et eu fa ff fi fj fo fr fy ga gd gl gn gu gv ha he hi ho hr ht hu
+hy hz ia id ie ig ii ik io is it iu ja jv ka kg ki kj kk kl km kn ko kr ks ku kv kw ky la lb
+lg li ln lo lt lu lv mg mh mi mk ml mn mr ms mt my na nb nd ne ng nl nn no nr nv ny oc oj om
+or os pa pi pl ps pt qu rm rn ro ru rw sa sc sd se sg si sk sl sm sn so sq sr ss st su sv sw
+ta te tg th ti tk tl tn to tr ts tt tw ty ug uk ur uz ve vi vo wa wo xh yi yo za zh zu`)
+
+func IsISO6391(code string) bool {
+	return slices.Contains(iso6391Codes, code)
+}
+
+// NormalizeLanguage reduces a language tag from a transcriber, such as "EN" or "pt-BR",
+// to its ISO 639-1 code, or returns "" when it has none.
+func NormalizeLanguage(value string) string {
+	code, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(value)), "-")
+	code, _, _ = strings.Cut(code, "_")
+	if !IsISO6391(code) {
+		return ""
+	}
+	return code
+}
+
+// ParseLanguage validates an ISO 639-1 code. When allowAuto is set, an empty value
+// means "auto", i.e. the transcriber should detect the language.
+func ParseLanguage(field, value string, allowAuto bool) (string, error) {
+	code := strings.ToLower(strings.TrimSpace(value))
+	if allowAuto && (code == "" || code == AutoLanguage) {
+		return AutoLanguage, nil
+	}
+
+	if !IsISO6391(code) {
+		if allowAuto {
+			return "", fmt.Errorf("%s must be an ISO 639-1 code or auto", field)
+		}
+		return "", fmt.Errorf("%s must be an ISO 639-1 code", field)
+	}
+
+	return code, nil
+}
@@ -2,22 +2,46 @@ package validator
 
 import (
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
+	"github.com/kwa0x2/SmartSRT-Backend/utils/subtitle"
 )
 
 type ConversionParams struct {
 	WordsPerLine        int
 	Punctuation         bool
 	ConsiderPunctuation bool
+	Format              types.SubtitleFormat
+	LineBreak           domain.LineBreakSettings
+	Language            string
+	Diarization         bool
+	TextProcessing      domain.TextProcessingOptions
 }
 
 func ValidateConversionParams(ctx *gin.Context) (*ConversionParams, error) {
+	return ParseConversionParams(ctx.PostForm)
+}
+
+// ParseConversionParams validates conversion params read through value, which lets
+// sources other than a multipart form (e.g. tus Upload-Metadata) share the same rules.
+func ParseConversionParams(value func(key string) string) (*ConversionParams, error) {
 	params := &ConversionParams{}
 
-	if wpl := ctx.PostForm("words_per_line"); wpl == "" {
-		return nil, fmt.Errorf("words per line is required")
+	lineBreak, err := parseLineBreakSettings(value)
+	if err != nil {
+		return nil, err
+	}
+	params.LineBreak = *lineBreak
+
+	if wpl := value("words_per_line"); wpl == "" {
+		if lineBreak.Mode == types.WordsLineBreak {
+			return nil, fmt.Errorf("words per line is required")
+		}
 	} else if val, err := strconv.Atoi(wpl); err != nil || val < 1 || val > 5 {
 		return nil, fmt.Errorf("words per line must be between 1 and 5")
 	} else {
@@ -28,7 +52,7 @@ func ValidateConversionParams(ctx *gin.Context) (*ConversionParams, error) {
 		"punctuation":          &params.Punctuation,
 		"consider_punctuation": &params.ConsiderPunctuation,
 	} {
-		if val := ctx.PostForm(field); val == "" {
+		if val := value(field); val == "" {
 			return nil, fmt.Errorf("%s is required", field)
 		} else if boolVal, err := strconv.ParseBool(val); err != nil {
 			return nil, fmt.Errorf("invalid %s value", field)
@@ -41,5 +65,94 @@ func ValidateConversionParams(ctx *gin.Context) (*ConversionParams, error) {
 		return nil, fmt.Errorf("consider_punctuation cannot be true when punctuation is false")
 	}
 
+	for field, ptr := range map[string]*bool{
+		"diarization":       &params.Diarization,
+		"mask_profanity":    &params.TextProcessing.MaskProfanity,
+		"normalize_numbers": &params.TextProcessing.NormalizeNumbers,
+		"remove_fillers":    &params.TextProcessing.RemoveFillers,
+		"sentence_case":     &params.TextProcessing.SentenceCase,
+	} {
+		if val := value(field); val != "" {
+			if *ptr, err = strconv.ParseBool(val); err != nil {
+				return nil, fmt.Errorf("invalid %s value", field)
+			}
+		}
+	}
+
+	if params.Language, err = ParseLanguage("language", value("language"), true); err != nil {
+		return nil, err
+	}
+
+	params.Format = types.SRTFormat
+	if format := value("format"); format != "" {
+		params.Format = types.SubtitleFormat(strings.ToLower(format))
+		if !subtitle.IsValidFormat(params.Format) {
+			return nil, fmt.Errorf("format must be one of srt, vtt, ass, ttml, sbv or txt")
+		}
+	}
+
 	return params, nil
 }
+
+// parseLineBreakSettings reads the segmentation mode. Chars mode limits are optional and
+// default to Netflix-style conventions.
+func parseLineBreakSettings(value func(key string) string) (*domain.LineBreakSettings, error) {
+	settings := &domain.LineBreakSettings{Mode: types.WordsLineBreak}
+
+	switch mode := types.LineBreakMode(strings.ToLower(value("line_break_mode"))); mode {
+	case "", types.WordsLineBreak:
+		return settings, nil
+	case types.CharsLineBreak:
+		settings.Mode = mode
+	default:
+		return nil, fmt.Errorf("line_break_mode must be words or chars")
+	}
+
+	settings.MaxCharsPerLine = 42
+	settings.MaxLinesPerCue = 2
+	settings.MinCueDuration = 5.0 / 6
+	settings.MaxCueDuration = 7
+	settings.MaxCPS = 17
+
+	if val := value("max_chars_per_line"); val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 10 || n > 80 {
+			return nil, fmt.Errorf("max_chars_per_line must be between 10 and 80")
+		}
+		settings.MaxCharsPerLine = n
+	}
+
+	if val := value("max_lines_per_cue"); val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 1 || n > 3 {
+			return nil, fmt.Errorf("max_lines_per_cue must be between 1 and 3")
+		}
+		settings.MaxLinesPerCue = n
+	}
+
+	for field, spec := range map[string]struct {
+		ptr      *float64
+		min, max float64
+	}{
+		"min_cue_duration": {&settings.MinCueDuration, 0, 10},
+		"max_cue_duration": {&settings.MaxCueDuration, 1, 30},
+		"max_cps":          {&settings.MaxCPS, 5, 40},
+	} {
+		val := value(field)
+		if val == "" {
+			continue
+		}
+		// ParseFloat accepts "NaN", which no range comparison rejects.
+		n, err := strconv.ParseFloat(val, 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) || n < spec.min || n > spec.max {
+			return nil, fmt.Errorf("%s must be between %g and %g", field, spec.min, spec.max)
+		}
+		*spec.ptr = n
+	}
+
+	if settings.MinCueDuration > settings.MaxCueDuration {
+		return nil, fmt.Errorf("min_cue_duration cannot be greater than max_cue_duration")
+	}
+
+	return settings, nil
+}
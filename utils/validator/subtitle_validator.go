@@ -0,0 +1,115 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kwa0x2/SmartSRT-Backend/domain"
+	"github.com/kwa0x2/SmartSRT-Backend/domain/types"
+)
+
+const (
+	DefaultMaxCharsPerLine = 42
+	DefaultMaxCPS          = 17
+)
+
+// ParseLintOptions reads optional lint limits, falling back to common broadcast defaults.
+func ParseLintOptions(value func(key string) string) (*domain.LintOptions, error) {
+	opts := &domain.LintOptions{
+		MaxCharsPerLine: DefaultMaxCharsPerLine,
+		MaxCPS:          DefaultMaxCPS,
+	}
+
+	if chars := value("max_chars_per_line"); chars != "" {
+		val, err := strconv.Atoi(chars)
+		if err != nil || val < 1 || val > 200 {
+			return nil, fmt.Errorf("max_chars_per_line must be between 1 and 200")
+		}
+		opts.MaxCharsPerLine = val
+	}
+
+	if cps := value("max_cps"); cps != "" {
+		val, err := strconv.ParseFloat(cps, 64)
+		if err != nil || math.IsNaN(val) || math.IsInf(val, 0) || val < 1 || val > 60 {
+			return nil, fmt.Errorf("max_cps must be between 1 and 60")
+		}
+		opts.MaxCPS = val
+	}
+
+	return opts, nil
+}
+
+var supportedFramerates = []float64{23.976, 24, 25, 29.97, 30, 50, 59.94, 60}
+
+const (
+	// maxTimingSeconds bounds every offset and sync point; no media we convert is longer than a day.
+	maxTimingSeconds = 24 * 60 * 60
+	// maxStretchFactor bounds how far a stretch may speed up or slow down the timeline.
+	maxStretchFactor = 4
+)
+
+// ParseTimingAdjustment reads a shift, stretch or framerate operation. Times are in seconds.
+func ParseTimingAdjustment(value func(key string) string) (*domain.TimingAdjustment, error) {
+	adjustment := &domain.TimingAdjustment{Operation: types.TimingOperation(strings.ToLower(value("operation")))}
+
+	number := func(field string, minimum float64) (float64, error) {
+		val := value(field)
+		if val == "" {
+			return 0, fmt.Errorf("%s is required", field)
+		}
+		n, err := strconv.ParseFloat(val, 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return 0, fmt.Errorf("invalid %s value", field)
+		}
+		if n < minimum || n > maxTimingSeconds {
+			return 0, fmt.Errorf("%s must be between %g and %d", field, minimum, maxTimingSeconds)
+		}
+		return n, nil
+	}
+
+	var err error
+	switch adjustment.Operation {
+	case types.ShiftTiming:
+		if adjustment.Offset, err = number("offset", -maxTimingSeconds); err != nil {
+			return nil, err
+		}
+	case types.StretchTiming:
+		for i := range adjustment.SyncPoints {
+			point := &adjustment.SyncPoints[i]
+			if point.From, err = number(fmt.Sprintf("sync%d_from", i+1), 0); err != nil {
+				return nil, err
+			}
+			if point.To, err = number(fmt.Sprintf("sync%d_to", i+1), 0); err != nil {
+				return nil, err
+			}
+		}
+		p1, p2 := adjustment.SyncPoints[0], adjustment.SyncPoints[1]
+		if p1.From == p2.From || p1.To == p2.To {
+			return nil, fmt.Errorf("sync points must be distinct before and after")
+		}
+		scale := (p2.To - p1.To) / (p2.From - p1.From)
+		if scale <= 0 {
+			return nil, fmt.Errorf("sync points must be in the same order before and after")
+		}
+		if scale < 1.0/maxStretchFactor || scale > maxStretchFactor {
+			return nil, fmt.Errorf("sync points may stretch the timing by at most a factor of %d", maxStretchFactor)
+		}
+	case types.FramerateTiming:
+		for field, ptr := range map[string]*float64{"from_fps": &adjustment.FromFPS, "to_fps": &adjustment.ToFPS} {
+			if *ptr, err = number(field, 0); err != nil {
+				return nil, err
+			}
+			// Every supported framerate is positive, so the ratio in the mapping is defined.
+			if !slices.Contains(supportedFramerates, *ptr) {
+				return nil, fmt.Errorf("%s must be one of 23.976, 24, 25, 29.97, 30, 50, 59.94 or 60", field)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("operation must be shift, stretch or framerate")
+	}
+
+	return adjustment, nil
+}